@@ -0,0 +1,117 @@
+// Package sm groups checks that span the SM2, SM3 and SM4 packages.
+package sm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+
+	"crypto/sm/sm2"
+	"crypto/sm/sm3"
+	"crypto/sm/sm4"
+)
+
+// Known answers. SM3 and SM4 are the examples of GB/T 32905 and
+// GB/T 32907; SM2 is the signature example of GB/T 32918.5 with the
+// default uid.
+var (
+	sm3KATInput = []byte("abc")
+	sm3KATHash  = "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"
+
+	sm4KATKey    = "0123456789abcdeffedcba9876543210"
+	sm4KATCipher = "681edf34d206965e86b3e94f536e4246"
+
+	sm2KATMsg = []byte("message digest")
+	sm2KATD   = "3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8"
+	sm2KATK   = "59276E27D506861A16680F3AD9C02DCCEF3CC1FA3CDBE4CE6D54B80DEAC1BC21"
+	sm2KATR   = "F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3"
+	sm2KATS   = "B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA"
+)
+
+// SelfTest runs known-answer tests of SM3, SM4 and SM2 signing and
+// verification, in that order, and returns an error naming the first
+// algorithm whose output is wrong. It is intended for power-on self-tests;
+// SM2 dominates its cost, a few tens of milliseconds.
+func SelfTest() error {
+	if err := selfTestSM3(); err != nil {
+		return errors.New("sm: SM3 self-test failed: " + err.Error())
+	}
+	if err := selfTestSM4(); err != nil {
+		return errors.New("sm: SM4 self-test failed: " + err.Error())
+	}
+	if err := selfTestSM2(); err != nil {
+		return errors.New("sm: SM2 self-test failed: " + err.Error())
+	}
+	return nil
+}
+
+func selfTestSM3() error {
+	want, _ := hex.DecodeString(sm3KATHash)
+	got := sm3.SumSM3(sm3KATInput)
+	if !bytes.Equal(got[:], want) {
+		return errors.New("wrong digest")
+	}
+	return nil
+}
+
+func selfTestSM4() error {
+	key, _ := hex.DecodeString(sm4KATKey)
+	want, _ := hex.DecodeString(sm4KATCipher)
+	c, err := sm4.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	// The GB/T 32907 example encrypts the key as the plaintext.
+	got := make([]byte, sm4.BlockSize)
+	c.Encrypt(got, key)
+	if !bytes.Equal(got, want) {
+		return errors.New("wrong ciphertext")
+	}
+	c.Decrypt(got, got)
+	if !bytes.Equal(got, key) {
+		return errors.New("wrong decryption")
+	}
+	return nil
+}
+
+func selfTestSM2() error {
+	priv := new(sm2.PrivateKey)
+	priv.Curve = sm2.P256Sm2()
+	priv.D, _ = new(big.Int).SetString(sm2KATD, 16)
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(priv.D.Bytes())
+
+	k, _ := new(big.Int).SetString(sm2KATK, 16)
+	nonce := nonceReader(k)
+	r, s, err := sm2.SignWithHash(nonce, priv, sm2KATMsg, nil, 0)
+	if err != nil {
+		return err
+	}
+	if nonce.Len() != 0 {
+		return errors.New("sm2 nonce sampling no longer reads 40 bytes; update nonceReader")
+	}
+	wantR, _ := new(big.Int).SetString(sm2KATR, 16)
+	wantS, _ := new(big.Int).SetString(sm2KATS, 16)
+	if r.Cmp(wantR) != 0 || s.Cmp(wantS) != 0 {
+		return errors.New("wrong signature")
+	}
+	if !sm2.VerifyWithHash(&priv.PublicKey, sm2KATMsg, nil, 0, r, s) {
+		return errors.New("valid signature rejected")
+	}
+	if sm2.VerifyWithHash(&priv.PublicKey, sm2KATMsg[1:], nil, 0, r, s) {
+		return errors.New("signature accepted for a different message")
+	}
+	return nil
+}
+
+// nonceReader returns a reader that makes sm2's nonce generation pick k.
+// sm2 has no exported way to supply k, so this relies on how its
+// randFieldElement samples: it reads exactly 40 bytes b and uses
+// (b mod (N-1)) + 1, as scalarReader in the sm2 tests also assumes. If
+// that sampling changes, the known answer no longer matches; selfTestSM2
+// reports a reader that was not read to the end, so the cause is clear.
+func nonceReader(k *big.Int) *bytes.Reader {
+	b := make([]byte, 40)
+	new(big.Int).Sub(k, big.NewInt(1)).FillBytes(b)
+	return bytes.NewReader(b)
+}
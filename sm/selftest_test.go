@@ -0,0 +1,33 @@
+package sm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Corrupting each known answer in turn must make SelfTest fail and name
+// the right algorithm.
+func TestSelfTestFailure(t *testing.T) {
+	for _, c := range []struct {
+		alg string
+		v   *string
+	}{
+		{"SM3", &sm3KATHash},
+		{"SM4", &sm4KATCipher},
+		{"SM2", &sm2KATS},
+	} {
+		saved := *c.v
+		*c.v = strings.Repeat("0", len(saved)-1) + "1"
+		err := SelfTest()
+		*c.v = saved
+		if err == nil || !strings.Contains(err.Error(), c.alg) {
+			t.Errorf("corrupted %s answer: SelfTest = %v", c.alg, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package sm2
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// batchBufSize is the read size of each GenerateKeys worker, enough for
+// about a hundred keys per read from the shared reader.
+const batchBufSize = 4096
+
+// lockedReader serialises reads from r, which need not be safe for
+// concurrent use.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(p)
+}
+
+// GenerateKeys generates n key pairs in parallel, one worker per available
+// CPU. rand, or DefaultRand if it is nil, is only ever read by one goroutine
+// at a time: each worker reads from it through its own buffer in
+// batchBufSize chunks, so rand need not be safe for concurrent use. Which
+// bytes end up in which key depends on scheduling, so a deterministic rand
+// does not give a deterministic result. The first error from rand is
+// returned and no keys are.
+func GenerateKeys(rand io.Reader, n int) ([]*PrivateKey, error) {
+	if n < 0 {
+		return nil, errors.New("sm2: negative key count")
+	}
+	keys := make([]*PrivateKey, n)
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	src := &lockedReader{r: randReader(rand)}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		failed   = make(chan struct{})
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			buf := bufio.NewReaderSize(src, batchBufSize)
+			for i := w; i < n; i += workers {
+				select {
+				case <-failed:
+					return
+				default:
+				}
+				k, err := GenerateKey(buf)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+					return
+				}
+				keys[i] = k
+			}
+		}(w)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return keys, nil
+}
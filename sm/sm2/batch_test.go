@@ -0,0 +1,58 @@
+package sm2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateKeys(t *testing.T) {
+	keys, err := GenerateKeys(nil, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 50 {
+		t.Fatalf("got %d keys, want 50", len(keys))
+	}
+	seen := make(map[string]bool)
+	for i, k := range keys {
+		if k == nil || !k.Curve.IsOnCurve(k.X, k.Y) {
+			t.Fatalf("key %d is invalid", i)
+		}
+		if seen[k.D.String()] {
+			t.Fatalf("key %d repeats an earlier key", i)
+		}
+		seen[k.D.String()] = true
+	}
+
+	if keys, err := GenerateKeys(nil, 0); err != nil || len(keys) != 0 {
+		t.Errorf("GenerateKeys(0) = %d keys, %v", len(keys), err)
+	}
+	if _, err := GenerateKeys(nil, -1); err == nil {
+		t.Error("GenerateKeys accepted a negative count")
+	}
+	if keys, err := GenerateKeys(errReader{}, 10); err == nil || keys != nil {
+		t.Errorf("GenerateKeys with a failing reader = %d keys, %v", len(keys), err)
+	}
+	// 100 bytes is enough for two keys, not ten.
+	if _, err := GenerateKeys(bytes.NewReader(make([]byte, 100)), 10); err == nil {
+		t.Error("GenerateKeys succeeded on a short reader")
+	}
+}
+
+func BenchmarkGenerateKeysSerial(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 64; j++ {
+			if _, err := GenerateKey(nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkGenerateKeysParallel(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := GenerateKeys(nil, 64); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package sm2
+
+import (
+	"errors"
+	"math/big"
+)
+
+var errBERSignature = errors.New("sm2: malformed BER signature")
+
+// ParseSignatureBER parses an SM2 signature encoded as an ASN.1 SEQUENCE of
+// two INTEGERs, r and s, accepting the BER forms that the DER parser used
+// by Verify and PublicKey.Verify rejects: long form or non-minimal lengths,
+// an indefinite length SEQUENCE terminated by an end-of-contents marker,
+// and INTEGERs padded with redundant leading zero bytes. The values
+// returned are the same whichever encoding was used, so re-encoding them
+// with SignTo or asn1.Marshal yields canonical DER.
+//
+// Negative integers and trailing data after the signature are rejected.
+func ParseSignatureBER(sig []byte) (r, s *big.Int, err error) {
+	tag, body, indefinite, rest, err := berHeader(sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag != 0x30 {
+		return nil, nil, errBERSignature
+	}
+	if !indefinite && len(rest) != 0 {
+		return nil, nil, errors.New("sm2: trailing data after signature")
+	}
+	if r, body, err = berInteger(body); err != nil {
+		return nil, nil, err
+	}
+	if s, body, err = berInteger(body); err != nil {
+		return nil, nil, err
+	}
+	if indefinite {
+		// body runs to the end of sig; it must hold exactly the
+		// end-of-contents octets.
+		if len(body) != 2 || body[0] != 0 || body[1] != 0 {
+			return nil, nil, errBERSignature
+		}
+	} else if len(body) != 0 {
+		return nil, nil, errBERSignature
+	}
+	return r, s, nil
+}
+
+// berHeader reads the identifier and length octets at the start of data.
+// Only single octet tags are supported. For a definite length it returns
+// the contents and the data that follows them; for an indefinite length,
+// which is only valid on a constructed encoding, body is everything after
+// the header and rest is nil.
+func berHeader(data []byte) (tag byte, body []byte, indefinite bool, rest []byte, err error) {
+	if len(data) < 2 || data[0]&0x1f == 0x1f {
+		return 0, nil, false, nil, errBERSignature
+	}
+	tag, data = data[0], data[1:]
+	l := int(data[0])
+	data = data[1:]
+	switch {
+	case l < 0x80:
+	case l == 0x80:
+		if tag&0x20 == 0 {
+			return 0, nil, false, nil, errBERSignature
+		}
+		return tag, data, true, nil, nil
+	default:
+		n := l & 0x7f
+		if n > len(data) {
+			return 0, nil, false, nil, errBERSignature
+		}
+		l = 0
+		for _, b := range data[:n] {
+			// Leading zero octets are allowed; the value itself must
+			// still fit within the input.
+			if l > len(data) {
+				return 0, nil, false, nil, errBERSignature
+			}
+			l = l<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if l > len(data) {
+		return 0, nil, false, nil, errBERSignature
+	}
+	return tag, data[:l], false, data[l:], nil
+}
+
+// berInteger reads a non-negative INTEGER with a definite length from the
+// start of data.
+func berInteger(data []byte) (*big.Int, []byte, error) {
+	// A primitive encoding such as INTEGER never has an indefinite length,
+	// so berHeader always returns its contents and rest here.
+	tag, body, _, rest, err := berHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag != 0x02 || len(body) == 0 || body[0]&0x80 != 0 {
+		return nil, nil, errBERSignature
+	}
+	return new(big.Int).SetBytes(body), rest, nil
+}
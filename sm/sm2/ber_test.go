@@ -0,0 +1,69 @@
+package sm2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseSignatureBER(t *testing.T) {
+	r, s := vectorR.Bytes(), vectorS.Bytes()
+	// Both values have their top bit set, so DER needs one zero byte of
+	// padding on each.
+	der := appendSignature(nil, vectorR, vectorS)
+
+	longForm := append([]byte{0x30, 0x81, 0x47, 0x02, 0x81, 0x21, 0x00}, r...)
+	longForm = append(append(longForm, 0x02, 0x21, 0x00), s...)
+
+	padded := append([]byte{0x30, 0x48, 0x02, 0x22, 0x00, 0x00}, r...)
+	padded = append(append(padded, 0x02, 0x22, 0x00, 0x00), s...)
+
+	indefinite := append([]byte{0x30, 0x80, 0x02, 0x82, 0x00, 0x21, 0x00}, r...)
+	indefinite = append(append(indefinite, 0x02, 0x21, 0x00), s...)
+	indefinite = append(indefinite, 0x00, 0x00)
+
+	for name, sig := range map[string][]byte{
+		"der":        der,
+		"long form":  longForm,
+		"padded":     padded,
+		"indefinite": indefinite,
+	} {
+		gotR, gotS, err := ParseSignatureBER(sig)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if gotR.Cmp(vectorR) != 0 || gotS.Cmp(vectorS) != 0 {
+			t.Errorf("%s: got (%x, %x)", name, gotR, gotS)
+		}
+		if name == "der" {
+			continue
+		}
+		if _, _, err := parseSignature(sig); err == nil {
+			t.Errorf("%s: strict parser accepted the BER encoding", name)
+		}
+		if !bytes.Equal(appendSignature(nil, gotR, gotS), der) {
+			t.Errorf("%s: re-encoding is not the canonical DER", name)
+		}
+	}
+}
+
+func TestParseSignatureBERRejects(t *testing.T) {
+	der := appendSignature(nil, vectorR, vectorS)
+	negative := []byte{0x30, 0x06, 0x02, 0x01, 0x80, 0x02, 0x01, 0x01}
+	noEOC := []byte{0x30, 0x80, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01}
+	primitiveIndefinite := []byte{0x30, 0x80, 0x02, 0x80, 0x01, 0x00, 0x00}
+	for name, sig := range map[string][]byte{
+		"empty":                nil,
+		"trailing data":        append(der[:len(der):len(der)], 0),
+		"truncated":            der[:len(der)-1],
+		"negative":             negative,
+		"missing EOC":          noEOC,
+		"primitive indefinite": primitiveIndefinite,
+		"not a sequence":       append([]byte{0x31}, der[1:]...),
+		"length overflow":      {0x30, 0x88, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	} {
+		if _, _, err := ParseSignatureBER(sig); err == nil {
+			t.Errorf("%s: ParseSignatureBER accepted %x", name, sig)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package sm2
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// A bundle packs a public key, a message and a signature together as three
+// fields, each a 4 byte big-endian length followed by that many bytes:
+//
+//	len(pub) || pub || len(msg) || msg || len(sig) || sig
+//
+// pub is a SEC 1 point as accepted by ParsePoint and sig is an ASN.1 DER
+// signature made with SignWithHash using the default uid and no pre-hash.
+
+var errMalformedBundle = errors.New("sm2: malformed signature bundle")
+
+// MarshalBundle encodes pub, msg and sig into the bundle format read by
+// VerifyBundle. pub is written uncompressed.
+func MarshalBundle(pub *PublicKey, msg, sig []byte) ([]byte, error) {
+	p, err := pub.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 12+len(p)+len(msg)+len(sig))
+	for _, field := range [][]byte{p, msg, sig} {
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(field)))
+		out = append(out, l[:]...)
+		out = append(out, field...)
+	}
+	return out, nil
+}
+
+// VerifyBundle parses a bundle and reports whether its signature is a valid
+// signature of its message by its public key. An error is returned only if
+// the bundle cannot be parsed, including when the key or signature field is
+// not a valid encoding; a well-formed bundle with a wrong signature returns
+// false and a nil error.
+func VerifyBundle(bundle []byte) (bool, error) {
+	var fields [3][]byte
+	rest := bundle
+	for i := range fields {
+		if len(rest) < 4 {
+			return false, errMalformedBundle
+		}
+		n := binary.BigEndian.Uint32(rest)
+		rest = rest[4:]
+		if uint64(n) > uint64(len(rest)) {
+			return false, errMalformedBundle
+		}
+		fields[i], rest = rest[:n], rest[n:]
+	}
+	if len(rest) != 0 {
+		return false, errMalformedBundle
+	}
+	pub, err := ParsePoint(fields[0])
+	if err != nil {
+		return false, err
+	}
+	r, s, err := parseSignature(fields[2])
+	if err != nil {
+		return false, errMalformedBundle
+	}
+	return VerifyWithHash(pub, fields[1], nil, 0, r, s), nil
+}
@@ -0,0 +1,42 @@
+package sm2
+
+import (
+	"testing"
+)
+
+func TestVerifyBundle(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	msg := []byte("stored record")
+	r, s, err := SignWithHash(nil, priv, msg, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := appendSignature(nil, r, s)
+	bundle, err := MarshalBundle(&priv.PublicKey, msg, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifyBundle(bundle); !ok || err != nil {
+		t.Fatalf("VerifyBundle = %v, %v; want true, nil", ok, err)
+	}
+
+	// The message starts after the key's prefix and encoding.
+	tampered := append([]byte{}, bundle...)
+	tampered[4+c1Len+4] ^= 1
+	if ok, err := VerifyBundle(tampered); ok || err != nil {
+		t.Errorf("tampered message: VerifyBundle = %v, %v; want false, nil", ok, err)
+	}
+
+	for name, b := range map[string][]byte{
+		"empty":            nil,
+		"short prefix":     bundle[:3],
+		"length too large": append([]byte{0xff, 0xff, 0xff, 0xff}, bundle[4:]...),
+		"truncated":        bundle[:len(bundle)-1],
+		"trailing data":    append(append([]byte{}, bundle...), 0),
+		"missing field":    bundle[:4+c1Len+4+len(msg)],
+	} {
+		if ok, err := VerifyBundle(b); ok || err == nil {
+			t.Errorf("%s: VerifyBundle = %v, %v; want an error", name, ok, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package sm2
+
+import (
+	"container/list"
+	"encoding/binary"
+	"sync"
+
+	"crypto/sm/sm3"
+)
+
+// CachingVerifier verifies ASN.1 DER signatures like VerifyBundle, with the
+// default uid and no pre-hash, and remembers the outcome for the most
+// recently seen (key, message, signature) tuples so that repeats are
+// answered without any point arithmetic. It is safe for concurrent use.
+//
+// Entries are keyed by SM3(pub || len(msg) || msg || sig), with pub in
+// uncompressed form and len(msg) as 8 big-endian bytes. Both valid and
+// invalid results are cached.
+type CachingVerifier struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[[sm3.Size]byte]*list.Element
+}
+
+type cacheEntry struct {
+	key   [sm3.Size]byte
+	valid bool
+}
+
+// NewCachingVerifier returns a CachingVerifier holding at most size results,
+// evicting the least recently used. size must be positive.
+func NewCachingVerifier(size int) *CachingVerifier {
+	if size <= 0 {
+		panic("sm2: cache size must be positive")
+	}
+	return &CachingVerifier{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[[sm3.Size]byte]*list.Element, size),
+	}
+}
+
+// Verify reports whether sig is a valid signature of msg by pub.
+func (v *CachingVerifier) Verify(pub *PublicKey, msg, sig []byte) bool {
+	p, err := pub.Marshal()
+	if err != nil {
+		return false
+	}
+	key := cacheKey(p, msg, sig)
+
+	v.mu.Lock()
+	if e, ok := v.entries[key]; ok {
+		v.order.MoveToFront(e)
+		valid := e.Value.(*cacheEntry).valid
+		v.mu.Unlock()
+		return valid
+	}
+	v.mu.Unlock()
+
+	valid := false
+	if r, s, err := parseSignature(sig); err == nil {
+		valid = VerifyWithHash(pub, msg, nil, 0, r, s)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.entries[key]; !ok {
+		v.entries[key] = v.order.PushFront(&cacheEntry{key: key, valid: valid})
+		if v.order.Len() > v.size {
+			oldest := v.order.Back()
+			v.order.Remove(oldest)
+			delete(v.entries, oldest.Value.(*cacheEntry).key)
+		}
+	}
+	return valid
+}
+
+// Len returns the number of cached results.
+func (v *CachingVerifier) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.order.Len()
+}
+
+func cacheKey(pub, msg, sig []byte) [sm3.Size]byte {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(msg)))
+	var key [sm3.Size]byte
+	copy(key[:], sm3.SumSM3Multi(pub, l[:], msg, sig))
+	return key
+}
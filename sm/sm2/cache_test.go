@@ -0,0 +1,57 @@
+package sm2
+
+import (
+	"testing"
+)
+
+func TestCachingVerifier(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	pub := &priv.PublicKey
+	v := NewCachingVerifier(2)
+
+	var sigs [3][]byte
+	msgs := [3][]byte{[]byte("record 0"), []byte("record 1"), []byte("record 2")}
+	for i := range sigs {
+		r, s, err := SignWithHash(nil, priv, msgs[i], nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs[i] = appendSignature(nil, r, s)
+	}
+
+	for i := 0; i < 2; i++ {
+		if !v.Verify(pub, msgs[0], sigs[0]) {
+			t.Fatalf("pass %d: valid signature rejected", i)
+		}
+		if v.Verify(pub, msgs[1], sigs[0]) {
+			t.Fatalf("pass %d: signature over another message accepted", i)
+		}
+	}
+	if v.Len() != 2 {
+		t.Fatalf("Len = %d after two distinct tuples, want 2", v.Len())
+	}
+
+	// A hit is answered from the cache: the cached result is returned even
+	// where a fresh verification would disagree.
+	p, _ := pub.Marshal()
+	v.mu.Lock()
+	v.entries[cacheKey(p, msgs[0], sigs[0])].Value.(*cacheEntry).valid = false
+	v.mu.Unlock()
+	if v.Verify(pub, msgs[0], sigs[0]) {
+		t.Error("Verify did not use the cached result")
+	}
+
+	v.Verify(pub, msgs[1], sigs[1])
+	v.Verify(pub, msgs[2], sigs[2])
+	if v.Len() != 2 {
+		t.Errorf("Len = %d, want the bound of 2", v.Len())
+	}
+	// msgs[0] was least recently used and must have been evicted.
+	if !v.Verify(pub, msgs[0], sigs[0]) {
+		t.Error("evicted entry was not verified afresh")
+	}
+
+	if v.Verify(&PublicKey{Curve: pub.Curve}, msgs[0], sigs[0]) {
+		t.Error("Verify accepted an invalid key")
+	}
+}
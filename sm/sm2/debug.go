@@ -0,0 +1,75 @@
+package sm2
+
+import (
+	"errors"
+	"math/big"
+)
+
+// VerifyResult holds the intermediate values of a verification for
+// diagnosing signatures that fail, typically because the two sides
+// computed e with different Z_A inputs.
+type VerifyResult struct {
+	// E is the integer e read from hash.
+	E *big.Int
+	// X1 is the x coordinate of s·G + t·P.
+	X1 *big.Int
+	// R is (E + X1) mod N, the r that a valid signature would have.
+	R *big.Int
+	// ExpectedR is the r of the signature being checked.
+	ExpectedR *big.Int
+	// Match reports whether R equals ExpectedR, which is the outcome of
+	// Verify.
+	Match bool
+	// Err says why verification stopped before computing R, if it did;
+	// the fields it did not reach are nil.
+	Err error
+}
+
+// VerifyDebug performs the same checks as Verify and returns the values it
+// computed along the way. All of them are public, so the result is safe to
+// log. Use Verify to decide whether to accept a signature.
+func VerifyDebug(pub *PublicKey, hash []byte, r, s *big.Int) *VerifyResult {
+	res := &VerifyResult{ExpectedR: r}
+	e, err := hashToInt(hash)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.E = e
+	if r == nil || s == nil {
+		res.Err = errors.New("sm2: missing signature value")
+		return res
+	}
+	if err := pub.validate(); err != nil {
+		res.Err = err
+		return res
+	}
+	if !isSM2Curve(pub.Curve) {
+		res.Err = errCurveMismatch
+		return res
+	}
+	c := pub.Curve
+	n := c.Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		res.Err = errors.New("sm2: r or s out of range [1, N-1]")
+		return res
+	}
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		res.Err = errZeroSum
+		return res
+	}
+	x11, y11 := c.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x12, y12 := c.ScalarBaseMult(s.Bytes())
+	x1, y1 := c.Add(x11, y11, x12, y12)
+	if isIdentity(x1, y1) {
+		res.Err = errPointInfinity
+		return res
+	}
+	res.X1 = x1
+	res.R = new(big.Int).Add(e, x1)
+	res.R.Mod(res.R, n)
+	res.Match = res.R.Cmp(r) == 0
+	return res
+}
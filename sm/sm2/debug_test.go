@@ -0,0 +1,46 @@
+package sm2
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestVerifyDebug(t *testing.T) {
+	priv := vectorKey()
+	pub := &priv.PublicKey
+	e, _ := hex.DecodeString(vectorE)
+
+	res := VerifyDebug(pub, e, vectorR, vectorS)
+	if res.Err != nil || !res.Match {
+		t.Fatalf("known good signature: Match = %v, Err = %v", res.Match, res.Err)
+	}
+	if res.E.Cmp(fromHex(vectorE)) != 0 || res.R.Cmp(vectorR) != 0 || res.ExpectedR != vectorR {
+		t.Errorf("E = %X, R = %X; want %s, %X", res.E, res.R, vectorE, vectorR)
+	}
+	// r = (e + x1) mod n with x1 the x of k·G.
+	x1, _ := priv.Curve.ScalarBaseMult(vectorK.Bytes())
+	if res.X1.Cmp(x1) != 0 {
+		t.Errorf("X1 = %X, want %X", res.X1, x1)
+	}
+
+	// A digest computed from a different Z_A yields an R that no longer
+	// matches.
+	other := append([]byte{}, e...)
+	other[0] ^= 1
+	res = VerifyDebug(pub, other, vectorR, vectorS)
+	if res.Err != nil || res.Match || res.R == nil || res.R.Cmp(vectorR) == 0 {
+		t.Errorf("known bad signature: Match = %v, R = %X, Err = %v", res.Match, res.R, res.Err)
+	}
+	if Verify(pub, other, vectorR, vectorS) != res.Match {
+		t.Error("VerifyDebug and Verify disagree")
+	}
+
+	res = VerifyDebug(pub, e, new(big.Int), vectorS)
+	if res.Err == nil || res.Match || res.X1 != nil {
+		t.Errorf("r = 0: Err = %v, Match = %v", res.Err, res.Match)
+	}
+	if res = VerifyDebug(pub, e[:20], vectorR, vectorS); res.Err != errHashLength {
+		t.Errorf("short hash: Err = %v", res.Err)
+	}
+}
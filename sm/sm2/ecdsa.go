@@ -0,0 +1,18 @@
+package sm2
+
+import "crypto/ecdsa"
+
+// ToECDSAPublicKey returns pub as an *ecdsa.PublicKey on the same curve and
+// point, sharing its big.Int values, for key management code that handles
+// keys through the crypto/ecdsa types. The ECDSA signing and verification
+// functions must not be used with the result: SM2 signatures are a
+// different scheme and do not verify as ECDSA signatures, nor the reverse.
+func ToECDSAPublicKey(pub *PublicKey) *ecdsa.PublicKey {
+	return &ecdsa.PublicKey{Curve: pub.Curve, X: pub.X, Y: pub.Y}
+}
+
+// ToECDSAPrivateKey returns priv as an *ecdsa.PrivateKey, with the same
+// sharing and caveat as ToECDSAPublicKey.
+func ToECDSAPrivateKey(priv *PrivateKey) *ecdsa.PrivateKey {
+	return &ecdsa.PrivateKey{PublicKey: *ToECDSAPublicKey(&priv.PublicKey), D: priv.D}
+}
@@ -0,0 +1,24 @@
+package sm2
+
+import "testing"
+
+func TestToECDSA(t *testing.T) {
+	priv := vectorKey()
+	k := ToECDSAPrivateKey(priv)
+	if k.Curve != priv.Curve {
+		t.Error("ECDSA key uses a different curve")
+	}
+	if *k.Curve.Params() != *P256Sm2().Params() {
+		t.Error("ECDSA curve parameters differ from SM2")
+	}
+	if k.X.Cmp(priv.X) != 0 || k.Y.Cmp(priv.Y) != 0 || k.D.Cmp(priv.D) != 0 {
+		t.Error("ECDSA key values differ from the SM2 key")
+	}
+	if !k.Curve.IsOnCurve(k.X, k.Y) {
+		t.Error("ECDSA public point is not on its curve")
+	}
+	pub := ToECDSAPublicKey(&priv.PublicKey)
+	if !pub.Equal(&k.PublicKey) {
+		t.Error("ToECDSAPublicKey differs from the public half of ToECDSAPrivateKey")
+	}
+}
@@ -0,0 +1,222 @@
+package sm2
+
+import (
+	"crypto/elliptic"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+
+	"crypto/sm/sm3"
+)
+
+// Ciphertexts are encoded as C1 || C3 || C2 as specified by GB/T 32918.4,
+// where C1 is the uncompressed ephemeral point, C3 the SM3 digest over
+// x2 || M || y2 and C2 the masked message.
+const (
+	c1Len = 1 + 2*32
+	c3Len = sm3.Size
+)
+
+var (
+	errZeroKDF          = errors.New("sm2: KDF output is all zero")
+	errCiphertextShort  = errors.New("sm2: ciphertext too short")
+	errInvalidC1        = errors.New("sm2: invalid ciphertext point C1")
+	errDecryptionFailed = errors.New("sm2: decryption failed")
+	errLengthMismatch   = errors.New("sm2: embedded plaintext length does not match")
+)
+
+// CiphertextLen returns the length of the ciphertext that Encrypt produces
+// for a message of msgLen bytes: the 65 byte uncompressed C1, the 32 byte
+// C3 and the msgLen bytes of C2.
+func CiphertextLen(msgLen int) int {
+	return c1Len + c3Len + msgLen
+}
+
+// Encrypt encrypts msg to pub as specified by GB/T 32918.4 and returns the
+// ciphertext in C1 || C3 || C2 form.
+func Encrypt(rand io.Reader, pub *PublicKey, msg []byte) ([]byte, error) {
+	for {
+		k, err := randFieldElement(pub.Curve, rand)
+		if err != nil {
+			return nil, err
+		}
+		out, err := encrypt(pub, k, msg)
+		if err == errZeroKDF {
+			continue
+		}
+		return out, err
+	}
+}
+
+// EncryptDeterministic encrypts msg to pub like Encrypt, but derives the
+// ephemeral scalar from SM3(len(context) || context || msg), with the length
+// as 2 big-endian bytes, instead of from a random source. Equal (context,
+// msg) pairs always produce equal ciphertexts, and the length prefix keeps
+// distinct pairs with the same concatenation apart. context may be at most
+// 65535 bytes.
+//
+// WARNING: this deliberately gives up semantic security. Anyone holding a
+// ciphertext can tell whether two ciphertexts encrypt the same message, and
+// anyone who can guess msg can confirm the guess by re-encrypting it, since
+// the public key is the only other input. Low entropy messages are therefore
+// exposed to offline dictionary attacks. Only use it for equality lookups
+// over high entropy tokens, and use a distinct context per index so that
+// ciphertexts cannot be correlated across indexes. The output is decrypted
+// with the ordinary Decrypt.
+func EncryptDeterministic(pub *PublicKey, msg, context []byte) ([]byte, error) {
+	m, err := contextMessage(msg, context)
+	if err != nil {
+		return nil, err
+	}
+	seed := sm3.SumSM3(m)
+	k := new(big.Int).SetBytes(seed[:])
+	n := new(big.Int).Sub(pub.Curve.Params().N, one)
+	k.Mod(k, n)
+	k.Add(k, one)
+	return encrypt(pub, k, msg)
+}
+
+// EncryptWithEphemeral encrypts msg to pub like Encrypt, using the caller's
+// ephemeral key pair instead of generating one, so the ciphertext is fully
+// determined by its inputs. It is meant for reproducing known answer vectors
+// and for protocols that fix the ephemeral in advance.
+//
+// WARNING: an ephemeral key must never be used for more than one message.
+// Two ciphertexts under the same ephemeral and public key share the KDF
+// keystream, so the XOR of their C2 parts is the XOR of the plaintexts.
+func EncryptWithEphemeral(pub *PublicKey, ephemeral *PrivateKey, msg []byte) ([]byte, error) {
+	if ephemeral == nil || ephemeral.D == nil {
+		return nil, errors.New("sm2: missing ephemeral key")
+	}
+	n := pub.Curve.Params().N
+	if ephemeral.D.Sign() <= 0 || ephemeral.D.Cmp(n) >= 0 {
+		return nil, errors.New("sm2: ephemeral scalar out of range")
+	}
+	return encrypt(pub, ephemeral.D, msg)
+}
+
+// encrypt performs SM2 encryption of msg to pub with the ephemeral scalar k.
+func encrypt(pub *PublicKey, k *big.Int, msg []byte) ([]byte, error) {
+	c := pub.Curve
+	if !c.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("sm2: public key is not on the curve")
+	}
+	x1, y1 := c.ScalarBaseMult(k.Bytes())
+	x2, y2 := c.ScalarMult(pub.X, pub.Y, k.Bytes())
+
+	x2Buf, y2Buf := fieldBytes(x2), fieldBytes(y2)
+	t := kdf(len(msg), x2Buf, y2Buf)
+	if len(t) > 0 && isAllZero(t) {
+		return nil, errZeroKDF
+	}
+
+	out := make([]byte, 0, CiphertextLen(len(msg)))
+	out = append(out, elliptic.Marshal(c, x1, y1)...)
+	out = append(out, c3(x2Buf, msg, y2Buf)...)
+	for i := range t {
+		t[i] ^= msg[i]
+	}
+	out = append(out, t...)
+	return out, nil
+}
+
+// Decrypt decrypts a C1 || C3 || C2 ciphertext produced by Encrypt.
+func Decrypt(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < c1Len+c3Len {
+		return nil, errCiphertextShort
+	}
+	c := priv.Curve
+	x1, y1 := elliptic.Unmarshal(c, ciphertext[:c1Len])
+	if x1 == nil {
+		return nil, errInvalidC1
+	}
+	x2, y2 := c.ScalarMult(x1, y1, priv.D.Bytes())
+
+	x2Buf, y2Buf := fieldBytes(x2), fieldBytes(y2)
+	c2 := ciphertext[c1Len+c3Len:]
+	msg := kdf(len(c2), x2Buf, y2Buf)
+	if len(msg) > 0 && isAllZero(msg) {
+		return nil, errDecryptionFailed
+	}
+	for i := range msg {
+		msg[i] ^= c2[i]
+	}
+	// C3 authenticates the plaintext, so it is compared in constant time
+	// and the unauthenticated plaintext is wiped if it does not match.
+	u := c3(x2Buf, msg, y2Buf)
+	if subtle.ConstantTimeCompare(u, ciphertext[c1Len:c1Len+c3Len]) != 1 {
+		for i := range msg {
+			msg[i] = 0
+		}
+		return nil, errDecryptionFailed
+	}
+	return msg, nil
+}
+
+// EncryptLengthPrefixed encrypts msg like Encrypt after prefixing it with
+// its length as a 4 byte big-endian integer, so that the ciphertext is 4
+// bytes longer than CiphertextLen(len(msg)). DecryptLengthPrefixed checks
+// the recovered length, which makes framing errors that cut or extend C2
+// show up as a length mismatch.
+func EncryptLengthPrefixed(rand io.Reader, pub *PublicKey, msg []byte) ([]byte, error) {
+	if uint64(len(msg)) > 0xffffffff {
+		return nil, errors.New("sm2: message too long for a 4 byte length prefix")
+	}
+	n := len(msg)
+	m := make([]byte, 0, 4+n)
+	m = append(m, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	return Encrypt(rand, pub, append(m, msg...))
+}
+
+// DecryptLengthPrefixed decrypts a ciphertext produced by
+// EncryptLengthPrefixed and returns the message without its prefix.
+func DecryptLengthPrefixed(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
+	m, err := Decrypt(priv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(m) < 4 {
+		return nil, errLengthMismatch
+	}
+	n := uint64(m[0])<<24 | uint64(m[1])<<16 | uint64(m[2])<<8 | uint64(m[3])
+	if n != uint64(len(m)-4) {
+		return nil, errLengthMismatch
+	}
+	return m[4:], nil
+}
+
+func c3(x2, msg, y2 []byte) []byte {
+	return sm3.SumSM3Multi(x2, msg, y2)
+}
+
+// kdf is the key derivation function of GB/T 32918.4 on SM3, returning
+// klen bytes derived from the concatenation of z.
+func kdf(klen int, z ...[]byte) []byte {
+	out := make([]byte, 0, klen+sm3.Size)
+	var ct [4]byte
+	h := sm3.New()
+	for i := uint32(1); len(out) < klen; i++ {
+		ct[0], ct[1], ct[2], ct[3] = byte(i>>24), byte(i>>16), byte(i>>8), byte(i)
+		h.Reset()
+		for _, b := range z {
+			h.Write(b)
+		}
+		h.Write(ct[:])
+		out = h.Sum(out)
+	}
+	return out[:klen]
+}
+
+// fieldBytes returns x as a 32 byte big-endian field element.
+func fieldBytes(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, 32))
+}
+
+func isAllZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
@@ -0,0 +1,161 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range [][]byte{
+		{},
+		[]byte("a"),
+		[]byte("encryption standard"),
+		bytes.Repeat([]byte("0123456789"), 10),
+	} {
+		ct, err := Encrypt(rand.Reader, &priv.PublicKey, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Decrypt(priv, ct)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("Decrypt = %q, want %q", got, msg)
+		}
+
+		ct[len(ct)-1] ^= 1
+		if _, err := Decrypt(priv, ct); err == nil && len(msg) > 0 {
+			t.Error("Decrypt accepted a tampered ciphertext")
+		}
+	}
+	if _, err := Decrypt(priv, make([]byte, 10)); err == nil {
+		t.Error("Decrypt accepted a truncated ciphertext")
+	}
+}
+
+func TestDecryptWrongC3(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	ct, err := Encrypt(rand.Reader, &priv.PublicKey, []byte("attacker supplied"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Whichever byte of C3 differs, and however many, decryption must fail
+	// with the same error.
+	for i := 0; i < c3Len; i++ {
+		bad := append([]byte{}, ct...)
+		bad[c1Len+i] ^= 0x80
+		if got, err := Decrypt(priv, bad); err != errDecryptionFailed || got != nil {
+			t.Errorf("C3 byte %d changed: Decrypt = %q, %v", i, got, err)
+		}
+	}
+	bad := append([]byte{}, ct...)
+	copy(bad[c1Len:c1Len+c3Len], make([]byte, c3Len))
+	if _, err := Decrypt(priv, bad); err != errDecryptionFailed {
+		t.Errorf("zero C3: err = %v, want %v", err, errDecryptionFailed)
+	}
+}
+
+func TestEncryptDeterministic(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	msg := []byte("token-0042")
+	ctx := []byte("users.email")
+
+	c1, err := EncryptDeterministic(pub, msg, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := EncryptDeterministic(pub, msg, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c1, c2) {
+		t.Error("EncryptDeterministic is not deterministic")
+	}
+	got, err := Decrypt(priv, c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("Decrypt = %q, want %q", got, msg)
+	}
+
+	other, _ := EncryptDeterministic(pub, []byte("token-0043"), ctx)
+	if bytes.Equal(c1, other) {
+		t.Error("different messages produced equal ciphertexts")
+	}
+	otherCtx, _ := EncryptDeterministic(pub, msg, []byte("users.phone"))
+	if bytes.Equal(c1, otherCtx) {
+		t.Error("different contexts produced equal ciphertexts")
+	}
+
+	// ("a", "bc") and ("ab", "c") concatenate to the same bytes but must
+	// not share an ephemeral key, which would show as an equal C1.
+	a, _ := EncryptDeterministic(pub, []byte("bc"), []byte("a"))
+	b, _ := EncryptDeterministic(pub, []byte("c"), []byte("ab"))
+	if bytes.Equal(a, b) || bytes.Equal(a[:c1Len], b[:c1Len]) {
+		t.Error("context and message boundary is ambiguous: equal C1")
+	}
+	if _, err := EncryptDeterministic(pub, msg, make([]byte, 0x10000)); err == nil {
+		t.Error("EncryptDeterministic accepted a 65536 byte context")
+	}
+}
+
+func TestCiphertextLen(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{0, 1, 31, 32, 33, 1000} {
+		ct, err := Encrypt(rand.Reader, &priv.PublicKey, make([]byte, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := CiphertextLen(n); got != len(ct) {
+			t.Errorf("CiphertextLen(%d) = %d, Encrypt produced %d bytes", n, got, len(ct))
+		}
+	}
+}
+
+func TestEncryptLengthPrefixed(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	for _, msg := range [][]byte{{}, []byte("framed message")} {
+		ct, err := EncryptLengthPrefixed(rand.Reader, &priv.PublicKey, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ct) != CiphertextLen(len(msg))+4 {
+			t.Errorf("ciphertext is %d bytes, want %d", len(ct), CiphertextLen(len(msg))+4)
+		}
+		got, err := DecryptLengthPrefixed(priv, ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("DecryptLengthPrefixed = %q, want %q", got, msg)
+		}
+		if _, err := DecryptLengthPrefixed(priv, ct[:len(ct)-1]); err == nil {
+			t.Error("DecryptLengthPrefixed accepted a truncated C2")
+		}
+	}
+
+	// A prefix that disagrees with the payload is caught even though the
+	// ciphertext itself is intact.
+	ct, _ := Encrypt(rand.Reader, &priv.PublicKey, []byte{0, 0, 0, 9, 'a', 'b'})
+	if _, err := DecryptLengthPrefixed(priv, ct); err != errLengthMismatch {
+		t.Errorf("mismatched prefix: err = %v, want errLengthMismatch", err)
+	}
+	ct, _ = Encrypt(rand.Reader, &priv.PublicKey, []byte{0, 0})
+	if _, err := DecryptLengthPrefixed(priv, ct); err != errLengthMismatch {
+		t.Errorf("short plaintext: err = %v, want errLengthMismatch", err)
+	}
+}
@@ -0,0 +1,98 @@
+package sm2
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// FormatGmSSLHex returns priv in the textual layout printed by GmSSL 3's
+// sm2_key_print, as used by its command line tools:
+//
+//	SM2PrivateKey
+//	    publicKey
+//	        x: <64 hex digits>
+//	        y: <64 hex digits>
+//	    privateKey: <64 hex digits>
+//
+// Each value is a 32 byte big-endian integer in upper case hex, indentation
+// is four spaces per level and every line ends with a newline.
+func FormatGmSSLHex(priv *PrivateKey) string {
+	var b strings.Builder
+	b.WriteString("SM2PrivateKey\n")
+	b.WriteString("    publicKey\n")
+	b.WriteString("        x: " + strings.ToUpper(hex.EncodeToString(fieldBytes(priv.X))) + "\n")
+	b.WriteString("        y: " + strings.ToUpper(hex.EncodeToString(fieldBytes(priv.Y))) + "\n")
+	b.WriteString("    privateKey: " + strings.ToUpper(hex.EncodeToString(fieldBytes(priv.D))) + "\n")
+	return b.String()
+}
+
+// ParseGmSSLHex parses a private key in the layout produced by
+// FormatGmSSLHex. Leading and trailing whitespace on each line is ignored
+// and hex digits may be in either case. The public key, if present, must
+// match the private key.
+func ParseGmSSLHex(s string) (*PrivateKey, error) {
+	errFormat := errors.New("sm2: malformed GmSSL key dump")
+	fields := make(map[string]*big.Int)
+	sc := bufio.NewScanner(strings.NewReader(s))
+	first := true
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if first {
+			if line != "SM2PrivateKey" {
+				return nil, errFormat
+			}
+			first = false
+			continue
+		}
+		if line == "publicKey" {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return nil, errFormat
+		}
+		name, value := line[:i], strings.TrimSpace(line[i+1:])
+		if name != "x" && name != "y" && name != "privateKey" {
+			return nil, errFormat
+		}
+		if _, dup := fields[name]; dup {
+			return nil, errFormat
+		}
+		raw, err := hex.DecodeString(value)
+		if err != nil || len(raw) != 32 {
+			return nil, errFormat
+		}
+		fields[name] = new(big.Int).SetBytes(raw)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	d, ok := fields["privateKey"]
+	if !ok {
+		return nil, errFormat
+	}
+	c := P256Sm2()
+	if d.Sign() == 0 || d.Cmp(c.Params().N) >= 0 {
+		return nil, errors.New("sm2: invalid private key value")
+	}
+	priv := new(PrivateKey)
+	priv.Curve = c
+	priv.D = d
+	priv.X, priv.Y = c.ScalarBaseMult(d.Bytes())
+
+	x, hasX := fields["x"]
+	y, hasY := fields["y"]
+	if hasX != hasY {
+		return nil, errFormat
+	}
+	if hasX && (x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0) {
+		return nil, errors.New("sm2: public key does not match private key")
+	}
+	return priv, nil
+}
@@ -0,0 +1,63 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+// gmsslSample is the GB/T 32918 example key, written out by hand following
+// GmSSL 3's sm2_key_print. It was not captured from a gmssl binary, so it
+// checks the layout as read from the GmSSL source, not interoperability
+// with a particular release; replace it with captured output when one is
+// available, recording the command used here.
+const gmsslSample = `SM2PrivateKey
+    publicKey
+        x: 09F9DF311E5421A150DD7D161E4BC5C672179FAD1833FC076BB08FF356F35020
+        y: CCEA490CE26775A52DC6EA718CC1AA600AED05FBF35E084A6632F6072DA9AD13
+    privateKey: 3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8
+`
+
+func TestParseGmSSLHex(t *testing.T) {
+	priv, err := ParseGmSSLHex(gmsslSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.D.Cmp(vectorD) != 0 {
+		t.Errorf("D = %X, want %X", priv.D, vectorD)
+	}
+	if got := FormatGmSSLHex(priv); got != gmsslSample {
+		t.Errorf("FormatGmSSLHex =\n%s\nwant\n%s", got, gmsslSample)
+	}
+
+	lowerHex := strings.Replace(gmsslSample, "3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8",
+		"3945208f7b2144b13f36e38ac6d39f95889393692860b51a42fb81ef4df7c5b8", 1)
+	if _, err := ParseGmSSLHex(lowerHex); err != nil {
+		t.Errorf("ParseGmSSLHex rejected lower case hex: %v", err)
+	}
+	if _, err := ParseGmSSLHex(strings.Replace(gmsslSample, "    ", "\t", -1)); err != nil {
+		t.Errorf("ParseGmSSLHex rejected tab indentation: %v", err)
+	}
+
+	for _, bad := range []string{
+		"",
+		strings.Replace(gmsslSample, "SM2PrivateKey", "SM2PublicKey", 1),
+		strings.Replace(gmsslSample, "x: 09", "x: 0A", 1),
+		strings.Replace(gmsslSample, "privateKey: 39", "privateKey: 3", 1),
+		strings.Replace(gmsslSample, "    privateKey", "    secretKey", 1),
+		gmsslSample + "    privateKey: 3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8\n",
+	} {
+		if _, err := ParseGmSSLHex(bad); err == nil {
+			t.Errorf("ParseGmSSLHex accepted\n%s", bad)
+		}
+	}
+
+	key, _ := GenerateKey(rand.Reader)
+	back, err := ParseGmSSLHex(FormatGmSSLHex(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.D.Cmp(key.D) != 0 || back.X.Cmp(key.X) != 0 || back.Y.Cmp(key.Y) != 0 {
+		t.Error("FormatGmSSLHex/ParseGmSSLHex round trip changed the key")
+	}
+}
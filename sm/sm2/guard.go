@@ -0,0 +1,86 @@
+package sm2
+
+import (
+	"crypto"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// guardHistory is the number of recent nonces a GuardedSigner remembers.
+const guardHistory = 4096
+
+// ErrNonceReuse is returned by GuardedSigner when a signing nonce repeats,
+// which means the random source is broken. The signature is withheld: two
+// signatures with the same nonce reveal the private key.
+var ErrNonceReuse = errors.New("sm2: signing nonce repeated, random source is broken")
+
+// GuardedSigner signs like Sign, but remembers the x-coordinates of the
+// last few thousand nonce points k·G it has used and refuses to release a
+// signature whose nonce point matches one of them. It detects a failing
+// random source; it is not a replacement for a good one. A GuardedSigner
+// is safe for concurrent use.
+type GuardedSigner struct {
+	priv *PrivateKey
+
+	mu   sync.Mutex
+	seen map[string]bool
+	ring [guardHistory]string
+	next int
+}
+
+// NewCollisionGuardedSigner returns a GuardedSigner for priv.
+func NewCollisionGuardedSigner(priv *PrivateKey) *GuardedSigner {
+	return &GuardedSigner{priv: priv, seen: make(map[string]bool)}
+}
+
+// Public returns the public key of the signer.
+func (g *GuardedSigner) Public() crypto.PublicKey {
+	return &g.priv.PublicKey
+}
+
+// SignHash signs hash like Sign, returning ErrNonceReuse instead of the
+// signature if its nonce has been seen before.
+func (g *GuardedSigner) SignHash(rand io.Reader, hash []byte) (r, s *big.Int, err error) {
+	r, s, err = Sign(rand, g.priv, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	// r = (e + x1) mod n, so x1 mod n = (r - e) mod n identifies k·G
+	// independently of the message.
+	e, _ := hashToInt(hash)
+	n := g.priv.Curve.Params().N
+	x1 := new(big.Int).Sub(r, e)
+	x1.Mod(x1, n)
+	if !g.record(string(fieldBytes(x1))) {
+		return nil, nil, ErrNonceReuse
+	}
+	return r, s, nil
+}
+
+// Sign implements crypto.Signer, returning an ASN.1 DER encoded signature
+// of digest. opts is ignored.
+func (g *GuardedSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	r, s, err := g.SignHash(rand, digest)
+	if err != nil {
+		return nil, err
+	}
+	return appendSignature(nil, r, s), nil
+}
+
+// record adds id to the history, reporting false if it was already there.
+func (g *GuardedSigner) record(id string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.seen[id] {
+		return false
+	}
+	if old := g.ring[g.next]; old != "" {
+		delete(g.seen, old)
+	}
+	g.ring[g.next] = id
+	g.next = (g.next + 1) % guardHistory
+	g.seen[id] = true
+	return true
+}
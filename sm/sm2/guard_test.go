@@ -0,0 +1,62 @@
+package sm2
+
+import (
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+// repeatReader returns the same bytes on every Read, like a stuck RNG.
+type repeatReader []byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r[i%len(r)]
+	}
+	return len(p), nil
+}
+
+func TestCollisionGuardedSigner(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	g := NewCollisionGuardedSigner(priv)
+	h1 := sm3.SumSM3([]byte("first"))
+	h2 := sm3.SumSM3([]byte("second"))
+
+	for i := 0; i < 10; i++ {
+		r, s, err := g.SignHash(nil, h1[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(&priv.PublicKey, h1[:], r, s) {
+			t.Fatal("guarded signature does not verify")
+		}
+	}
+
+	stuck := repeatReader("entropy source stuck in a loop!!")
+	if _, err := g.Sign(stuck, h1[:], nil); err != nil {
+		t.Fatal(err)
+	}
+	// A different message with the same nonce must still be caught.
+	if _, err := g.Sign(stuck, h2[:], nil); err != ErrNonceReuse {
+		t.Errorf("second signature with a repeated nonce: err = %v, want ErrNonceReuse", err)
+	}
+	if _, _, err := g.SignHash(stuck, h1[:]); err != ErrNonceReuse {
+		t.Errorf("repeated signature of the same message: err = %v, want ErrNonceReuse", err)
+	}
+}
+
+func TestGuardHistoryEviction(t *testing.T) {
+	g := NewCollisionGuardedSigner(nil)
+	if !g.record("a") || g.record("a") {
+		t.Fatal("record did not detect a repeat")
+	}
+	for i := 0; i < guardHistory; i++ {
+		g.record(string(rune(0x10000 + i)))
+	}
+	if len(g.seen) != guardHistory {
+		t.Errorf("history holds %d entries, want %d", len(g.seen), guardHistory)
+	}
+	if !g.record("a") {
+		t.Error("entry was not evicted after guardHistory newer entries")
+	}
+}
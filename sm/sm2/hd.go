@@ -0,0 +1,93 @@
+package sm2
+
+import (
+	"crypto/hmac"
+	"errors"
+	"math/big"
+
+	"crypto/sm/sm3"
+)
+
+// HardenedKeyStart is the first hardened child index. Hardened children are
+// derived from the parent private key rather than its public key.
+const HardenedKeyStart = 1 << 31
+
+// ExtendedKey is an SM2 private key together with the chain code used to
+// derive its children, in the manner of BIP 32 with HMAC-SM3 in place of
+// HMAC-SHA512. Since SM3 outputs 32 bytes, the 64 bytes I = IL || IR that
+// BIP 32 takes from one HMAC are formed as
+//
+//	IL = HMAC-SM3(key, 0x00 || data)
+//	IR = HMAC-SM3(key, 0x01 || data)
+//
+// where IL tweaks the private key and IR becomes the new chain code.
+type ExtendedKey struct {
+	PrivateKey
+	ChainCode [32]byte
+}
+
+var errInvalidChild = errors.New("sm2: derived key is invalid, use the next index")
+
+// NewMasterKey derives the root of a key hierarchy from seed, which should
+// hold at least 16 bytes of entropy, using the HMAC key "SM2 seed".
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 {
+		return nil, errors.New("sm2: seed must be at least 16 bytes")
+	}
+	il, ir := hdHMAC([]byte("SM2 seed"), seed)
+	n := P256Sm2().Params().N
+	d := new(big.Int).SetBytes(il)
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return nil, errors.New("sm2: seed yields an invalid master key")
+	}
+	return newExtendedKey(d, ir), nil
+}
+
+// DeriveChild returns the child of k with the given index. Indexes from
+// HardenedKeyStart on give hardened children. In the rare case that the
+// derivation yields an invalid key, DeriveChild returns an error and the
+// caller should move on to the next index, as BIP 32 specifies.
+func (k *ExtendedKey) DeriveChild(index uint32) (*ExtendedKey, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedKeyStart {
+		data = append(data, 0)
+		data = append(data, fieldBytes(k.D)...)
+	} else {
+		data = append(data, compressPoint(k.X, k.Y)...)
+	}
+	data = append(data, byte(index>>24), byte(index>>16), byte(index>>8), byte(index))
+
+	il, ir := hdHMAC(k.ChainCode[:], data)
+	n := k.Curve.Params().N
+	tweak := new(big.Int).SetBytes(il)
+	if tweak.Cmp(n) >= 0 {
+		return nil, errInvalidChild
+	}
+	d := tweak.Add(tweak, k.D)
+	d.Mod(d, n)
+	if d.Sign() == 0 {
+		return nil, errInvalidChild
+	}
+	return newExtendedKey(d, ir), nil
+}
+
+func newExtendedKey(d *big.Int, chainCode []byte) *ExtendedKey {
+	k := new(ExtendedKey)
+	k.Curve = P256Sm2()
+	k.D = d
+	k.X, k.Y = k.Curve.ScalarBaseMult(d.Bytes())
+	copy(k.ChainCode[:], chainCode)
+	return k
+}
+
+func hdHMAC(key, data []byte) (il, ir []byte) {
+	mac := hmac.New(sm3.New, key)
+	mac.Write([]byte{0})
+	mac.Write(data)
+	il = mac.Sum(nil)
+	mac.Reset()
+	mac.Write([]byte{1})
+	mac.Write(data)
+	ir = mac.Sum(nil)
+	return il, ir
+}
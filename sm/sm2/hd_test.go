@@ -0,0 +1,71 @@
+package sm2
+
+import (
+	"bytes"
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func TestDeriveChild(t *testing.T) {
+	seed := []byte("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := NewMasterKey(seed)
+	if master.D.Cmp(again.D) != 0 || master.ChainCode != again.ChainCode {
+		t.Fatal("NewMasterKey is not deterministic")
+	}
+
+	seen := make(map[string]bool)
+	for _, index := range []uint32{0, 1, 2, HardenedKeyStart, HardenedKeyStart + 1} {
+		child, err := master.DeriveChild(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		twin, err := master.DeriveChild(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if child.D.Cmp(twin.D) != 0 || child.ChainCode != twin.ChainCode {
+			t.Errorf("child %d is not deterministic", index)
+		}
+		if seen[child.D.String()] || child.D.Cmp(master.D) == 0 {
+			t.Errorf("child %d repeats another key", index)
+		}
+		seen[child.D.String()] = true
+		if bytes.Equal(child.ChainCode[:], master.ChainCode[:]) {
+			t.Errorf("child %d reuses the parent chain code", index)
+		}
+
+		// The child is a usable SM2 key.
+		if err := child.PublicKey.CheckOrder(); err != nil {
+			t.Errorf("child %d: %v", index, err)
+		}
+		hashed := sm3.SumSM3([]byte("wallet"))
+		r, s, err := Sign(nil, &child.PrivateKey, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(&child.PublicKey, hashed[:], r, s) {
+			t.Errorf("child %d: signature does not verify", index)
+		}
+	}
+
+	grandchild, err := master.DeriveChild(HardenedKeyStart)
+	if err == nil {
+		grandchild, err = grandchild.DeriveChild(7)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := master.DeriveChild(7)
+	if grandchild.D.Cmp(other.D) == 0 {
+		t.Error("m/0'/7 equals m/7")
+	}
+
+	if _, err := NewMasterKey(seed[:15]); err == nil {
+		t.Error("NewMasterKey accepted a 15 byte seed")
+	}
+}
@@ -0,0 +1,45 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"io"
+	"math/big"
+
+	"crypto/sm/sm3"
+)
+
+// SignHedged signs msg for the identity uid like SignWithHash with h == 0,
+// with a nonce that combines a deterministic and a random part. The 40
+// bytes from which the nonce is reduced are
+//
+//	HMAC-SM3(D, 0x00 || e) || HMAC-SM3(D, 0x01 || e)[:8]
+//
+// XORed with 40 bytes read from rand, where D is the 32 byte private key
+// and e = SM3(Z_A || msg). If rand is broken and returns predictable
+// output the nonce is still secret, as with deterministic signing; if it
+// works, signatures of the same message differ, which frustrates fault
+// attacks that rely on repeating a signature. The result verifies with
+// VerifyWithHash.
+func SignHedged(rand io.Reader, priv *PrivateKey, msg, uid []byte) (r, s *big.Int, err error) {
+	e, err := messageDigest(&priv.PublicKey, msg, uid, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	seed := make([]byte, 40)
+	if _, err := io.ReadFull(randReader(rand), seed); err != nil {
+		return nil, nil, err
+	}
+	key := priv.D.FillBytes(make([]byte, 32))
+	det := make([]byte, 0, 2*sm3.Size)
+	for _, tag := range []byte{0x00, 0x01} {
+		mac := hmac.New(sm3.New, key)
+		mac.Write([]byte{tag})
+		mac.Write(e)
+		det = mac.Sum(det)
+	}
+	for i := range seed {
+		seed[i] ^= det[i]
+	}
+	return Sign(bytes.NewReader(seed), priv, e)
+}
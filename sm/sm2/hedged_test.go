@@ -0,0 +1,43 @@
+package sm2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSignHedged(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	msg, uid := []byte("hedged"), []byte("alice@example.com")
+
+	r1, s1, err := SignHedged(nil, priv, msg, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, s2, err := SignHedged(nil, priv, msg, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyWithHash(&priv.PublicKey, msg, uid, 0, r1, s1) || !VerifyWithHash(&priv.PublicKey, msg, uid, 0, r2, s2) {
+		t.Fatal("hedged signature does not verify")
+	}
+	if r1.Cmp(r2) == 0 {
+		t.Error("two hedged signatures of the same message are equal")
+	}
+
+	// With a constant random source the deterministic part still gives a
+	// nonce that depends on the key and message.
+	zeros := func() *bytes.Reader { return bytes.NewReader(make([]byte, 40)) }
+	ra, _, _ := SignHedged(zeros(), priv, msg, uid)
+	rb, _, _ := SignHedged(zeros(), priv, msg, uid)
+	rc, _, _ := SignHedged(zeros(), priv, []byte("other"), uid)
+	if ra.Cmp(rb) != 0 {
+		t.Error("hedged signing with a zero source is not deterministic")
+	}
+	if ra.Cmp(rc) == 0 {
+		t.Error("nonce does not depend on the message")
+	}
+
+	if _, _, err := SignHedged(errReader{}, priv, msg, uid); err == nil {
+		t.Error("SignHedged hid a failing random source")
+	}
+}
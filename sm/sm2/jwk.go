@@ -0,0 +1,85 @@
+package sm2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// jwk is the JSON Web Key form of an SM2 key, following RFC 7518 section 6.2
+// with the curve name "SM2". Coordinates and the private scalar are 32 byte
+// big-endian values, base64url encoded without padding.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d,omitempty"`
+}
+
+var errJWK = errors.New("sm2: invalid JWK")
+
+// MarshalJWK returns priv as a JSON Web Key with kty "EC" and crv "SM2",
+// including the private scalar d.
+func (priv *PrivateKey) MarshalJWK() ([]byte, error) {
+	if err := priv.PublicKey.validate(); err != nil {
+		return nil, err
+	}
+	enc := base64.RawURLEncoding
+	return json.Marshal(jwk{
+		Kty: "EC",
+		Crv: "SM2",
+		X:   enc.EncodeToString(fieldBytes(priv.X)),
+		Y:   enc.EncodeToString(fieldBytes(priv.Y)),
+		D:   enc.EncodeToString(fieldBytes(priv.D)),
+	})
+}
+
+// ParseJWK parses an SM2 private key in the form produced by MarshalJWK.
+// Each of x, y and d must decode to exactly 32 bytes, and x and y must be
+// the public key belonging to d.
+func ParseJWK(data []byte) (*PrivateKey, error) {
+	var k jwk
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	if k.Kty != "EC" || k.Crv != "SM2" {
+		return nil, errors.New("sm2: JWK is not an SM2 key")
+	}
+	x, err := decodeJWKField(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeJWKField(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	if k.D == "" {
+		return nil, errors.New("sm2: JWK has no private key")
+	}
+	d, err := decodeJWKField(k.D)
+	if err != nil {
+		return nil, err
+	}
+	c := P256Sm2()
+	if d.Sign() == 0 || d.Cmp(c.Params().N) >= 0 {
+		return nil, errors.New("sm2: invalid private key value")
+	}
+	priv := new(PrivateKey)
+	priv.Curve = c
+	priv.D = d
+	priv.X, priv.Y = c.ScalarBaseMult(d.Bytes())
+	if x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0 {
+		return nil, errors.New("sm2: public key does not match private key")
+	}
+	return priv, nil
+}
+
+func decodeJWKField(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		return nil, errJWK
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,59 @@
+package sm2
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJWK(t *testing.T) {
+	priv := vectorKey()
+	data, err := priv.MarshalJWK()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"kty":"EC","crv":"SM2",` +
+		`"x":"CfnfMR5UIaFQ3X0WHkvFxnIXn60YM_wHa7CP81bzUCA",` +
+		`"y":"zOpJDOJndaUtxupxjMGqYArtBfvzXghKZjL2By2prRM",` +
+		`"d":"OUUgj3shRLE_NuOKxtOflYiTk2koYLUaQvuB7033xbg"}`
+	if string(data) != want {
+		t.Errorf("MarshalJWK = %s, want %s", data, want)
+	}
+
+	got, err := ParseJWK(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.D.Cmp(priv.D) != 0 || got.X.Cmp(priv.X) != 0 || got.Y.Cmp(priv.Y) != 0 {
+		t.Error("ParseJWK(MarshalJWK(priv)) != priv")
+	}
+
+	// Keys whose values have leading zero bytes keep fixed-width fields.
+	for i := 0; i < 20; i++ {
+		k, _ := GenerateKey(nil)
+		data, _ := k.MarshalJWK()
+		var fields map[string]string
+		json.Unmarshal(data, &fields)
+		for _, f := range []string{"x", "y", "d"} {
+			if len(fields[f]) != 43 {
+				t.Fatalf("%s is %d characters, want 43", f, len(fields[f]))
+			}
+		}
+		if _, err := ParseJWK(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, bad := range []string{
+		strings.Replace(want, `"EC"`, `"OKP"`, 1),
+		strings.Replace(want, `"SM2"`, `"P-256"`, 1),
+		strings.Replace(want, `"d":"OUUgj3shRLE_NuOKxtOflYiTk2koYLUaQvuB7033xbg"`, `"d":"OUUhj3shRLE_NuOKxtOflYiTk2koYLUaQvuB7033xbg"`, 1),
+		strings.Replace(want, `81bzUCA"`, `81bzUCA="`, 1),
+		strings.Replace(want, `,"d":"OUUgj3shRLE_NuOKxtOflYiTk2koYLUaQvuB7033xbg"`, ``, 1),
+		`{"kty":"EC","crv":"SM2","x":"AA","y":"AA","d":"AA"}`,
+	} {
+		if _, err := ParseJWK([]byte(bad)); err == nil {
+			t.Errorf("ParseJWK(%s) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package sm2
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+
+	"crypto/sm/sm4"
+)
+
+// SharedKeySize is the length in bytes of the keys produced by Encapsulate
+// and Decapsulate. It matches the SM4 key size.
+const SharedKeySize = 16
+
+// Encapsulate generates a fresh shared key for pub. It returns the
+// encapsulation, which is the uncompressed ephemeral point C1, and a key
+// derived with the GB/T 32918.4 KDF from the shared point (x2, y2).
+func Encapsulate(rand io.Reader, pub *PublicKey) (ciphertext, sharedKey []byte, err error) {
+	c := pub.Curve
+	if !c.IsOnCurve(pub.X, pub.Y) {
+		return nil, nil, errPointInvalid
+	}
+	for {
+		var k *big.Int
+		k, err = randFieldElement(c, rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		x1, y1 := c.ScalarBaseMult(k.Bytes())
+		x2, y2 := c.ScalarMult(pub.X, pub.Y, k.Bytes())
+		sharedKey = kdf(SharedKeySize, fieldBytes(x2), fieldBytes(y2))
+		if isAllZero(sharedKey) {
+			continue
+		}
+		return elliptic.Marshal(c, x1, y1), sharedKey, nil
+	}
+}
+
+// Decapsulate recovers the shared key from an encapsulation produced by
+// Encapsulate.
+func Decapsulate(priv *PrivateKey, ciphertext []byte) (sharedKey []byte, err error) {
+	if len(ciphertext) != c1Len {
+		return nil, errInvalidC1
+	}
+	c := priv.Curve
+	x1, y1 := elliptic.Unmarshal(c, ciphertext)
+	if x1 == nil {
+		return nil, errInvalidC1
+	}
+	x2, y2 := c.ScalarMult(x1, y1, priv.D.Bytes())
+	sharedKey = kdf(SharedKeySize, fieldBytes(x2), fieldBytes(y2))
+	if isAllZero(sharedKey) {
+		return nil, errDecryptionFailed
+	}
+	return sharedKey, nil
+}
+
+// EncryptLarge encrypts msg of any length to pub using a hybrid scheme: a
+// fresh SM4 key is encapsulated with Encapsulate and the message is sealed
+// with SM4-GCM under it. The result is C1 || nonce || GCM ciphertext, with
+// C1 authenticated as additional data.
+func EncryptLarge(rand io.Reader, pub *PublicKey, msg []byte) ([]byte, error) {
+	c1, key, err := Encapsulate(rand, pub)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := sm4.NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(c1)+aead.NonceSize(), len(c1)+aead.NonceSize()+len(msg)+aead.Overhead())
+	copy(out, c1)
+	nonce := out[len(c1):]
+	if _, err := io.ReadFull(randReader(rand), nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, msg, c1), nil
+}
+
+// DecryptLarge decrypts and authenticates a message produced by
+// EncryptLarge.
+func DecryptLarge(priv *PrivateKey, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < c1Len {
+		return nil, errCiphertextShort
+	}
+	c1 := ciphertext[:c1Len]
+	key, err := Decapsulate(priv, c1)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := sm4.NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := ciphertext[c1Len:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, errCiphertextShort
+	}
+	msg, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], c1)
+	if err != nil {
+		return nil, errors.New("sm2: message authentication failed")
+	}
+	return msg, nil
+}
+
+// SharedPoint returns the point D·peer, the raw Diffie-Hellman style shared
+// secret between priv and peer, for use with a caller-chosen KDF. Callers
+// should not use the raw coordinates directly as key material.
+func (priv *PrivateKey) SharedPoint(peer *PublicKey) (x, y *big.Int, err error) {
+	if peer == nil || peer.X == nil || peer.Y == nil {
+		return nil, nil, errPointInvalid
+	}
+	c := priv.Curve
+	if !c.IsOnCurve(peer.X, peer.Y) {
+		return nil, nil, errPointInvalid
+	}
+	x, y = c.ScalarMult(peer.X, peer.Y, priv.D.Bytes())
+	if isIdentity(x, y) {
+		return nil, nil, errPointInfinity
+	}
+	return x, y, nil
+}
@@ -0,0 +1,118 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestEncapsulateDecapsulate(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, key, err := Encapsulate(rand.Reader, &priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != SharedKeySize {
+		t.Fatalf("shared key length = %d, want %d", len(key), SharedKeySize)
+	}
+	got, err := Decapsulate(priv, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("Decapsulate = %x, want %x", got, key)
+	}
+
+	ct2, key2, err := Encapsulate(rand.Reader, &priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ct, ct2) || bytes.Equal(key, key2) {
+		t.Error("two encapsulations produced the same output")
+	}
+
+	other, _ := GenerateKey(rand.Reader)
+	if k, err := Decapsulate(other, ct); err == nil && bytes.Equal(k, key) {
+		t.Error("a different private key recovered the shared key")
+	}
+	if _, err := Decapsulate(priv, ct[:64]); err == nil {
+		t.Error("Decapsulate accepted a truncated encapsulation")
+	}
+	bad := append([]byte{}, ct...)
+	bad[64] ^= 1
+	if _, err := Decapsulate(priv, bad); err == nil {
+		t.Error("Decapsulate accepted an off-curve point")
+	}
+}
+
+func TestEncryptLarge(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := make([]byte, 1<<20)
+	if _, err := rand.Read(msg); err != nil {
+		t.Fatal(err)
+	}
+	ct, err := EncryptLarge(rand.Reader, &priv.PublicKey, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecryptLarge(priv, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Fatal("DecryptLarge did not return the original message")
+	}
+
+	for _, i := range []int{0, 64, c1Len, c1Len + 12, len(ct) / 2, len(ct) - 1} {
+		bad := append([]byte{}, ct...)
+		bad[i] ^= 1
+		if _, err := DecryptLarge(priv, bad); err == nil {
+			t.Errorf("DecryptLarge accepted a ciphertext modified at byte %d", i)
+		}
+	}
+	if _, err := DecryptLarge(priv, ct[:c1Len+20]); err == nil {
+		t.Error("DecryptLarge accepted a truncated ciphertext")
+	}
+
+	empty, err := EncryptLarge(rand.Reader, &priv.PublicKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptLarge(priv, empty); err != nil || len(got) != 0 {
+		t.Errorf("DecryptLarge of an empty message = %x, %v", got, err)
+	}
+}
+
+func TestSharedPoint(t *testing.T) {
+	alice, _ := GenerateKey(rand.Reader)
+	bob, _ := GenerateKey(rand.Reader)
+	x1, y1, err := alice.SharedPoint(&bob.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x2, y2, err := bob.SharedPoint(&alice.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x1.Cmp(x2) != 0 || y1.Cmp(y2) != 0 {
+		t.Error("the two parties computed different shared points")
+	}
+	if !alice.Curve.IsOnCurve(x1, y1) {
+		t.Error("shared point is not on the curve")
+	}
+
+	bad := &PublicKey{Curve: P256Sm2(), X: big.NewInt(1), Y: big.NewInt(1)}
+	if _, _, err := alice.SharedPoint(bad); err == nil {
+		t.Error("SharedPoint accepted an off-curve peer key")
+	}
+	if _, _, err := alice.SharedPoint(&PublicKey{Curve: P256Sm2()}); err == nil {
+		t.Error("SharedPoint accepted a peer key without coordinates")
+	}
+}
@@ -0,0 +1,51 @@
+package sm2
+
+import (
+	"io"
+	"math/big"
+)
+
+// SignToRSLittleEndian signs hash like Sign and returns r || s with each
+// value as 32 little-endian bytes, for tools that expect that layout. It is
+// SignSKF with each half byte-reversed: the value is first zero padded to
+// 32 bytes big-endian and then reversed, so any padding ends up as
+// trailing zero bytes.
+func SignToRSLittleEndian(rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := Sign(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	return appendRSLittleEndian(make([]byte, 0, rawSignatureSize), r, s), nil
+}
+
+// VerifyRSLittleEndian reports whether sig, in the 64 byte layout produced
+// by SignToRSLittleEndian, is a valid signature of hash by pub.
+func VerifyRSLittleEndian(pub *PublicKey, hash, sig []byte) bool {
+	if len(sig) != rawSignatureSize {
+		return false
+	}
+	var buf [32]byte
+	copy(buf[:], sig[:32])
+	reverse(buf[:])
+	r := new(big.Int).SetBytes(buf[:])
+	copy(buf[:], sig[32:])
+	reverse(buf[:])
+	s := new(big.Int).SetBytes(buf[:])
+	return Verify(pub, hash, r, s)
+}
+
+func appendRSLittleEndian(dst []byte, r, s *big.Int) []byte {
+	for _, v := range []*big.Int{r, s} {
+		var buf [32]byte
+		v.FillBytes(buf[:])
+		reverse(buf[:])
+		dst = append(dst, buf[:]...)
+	}
+	return dst
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
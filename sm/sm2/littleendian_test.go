@@ -0,0 +1,45 @@
+package sm2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestSignToRSLittleEndian(t *testing.T) {
+	priv := vectorKey()
+	e, err := messageDigest(&priv.PublicKey, []byte("message digest"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignToRSLittleEndian(scalarReader(vectorK), priv, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The GB/T 32918 example signature with r and s each byte-reversed.
+	want, _ := hex.DecodeString("B320E7EEAC7EAC4341B7D52738DA4459A181BBE113C5EA0E63C4D248063BA0F5" +
+		"AAC1BB85C4690B84D4427F1FFD3890BBA11C420DBC823176D82F21DF29AAB6B1")
+	if !bytes.Equal(sig, want) {
+		t.Errorf("SignToRSLittleEndian = %X, want %X", sig, want)
+	}
+	if !VerifyRSLittleEndian(&priv.PublicKey, e, sig) {
+		t.Error("VerifyRSLittleEndian rejected the example signature")
+	}
+	skf, _ := SignSKF(scalarReader(vectorK), priv, e)
+	if VerifyRSLittleEndian(&priv.PublicKey, e, skf) {
+		t.Error("VerifyRSLittleEndian accepted the big-endian layout")
+	}
+	if VerifyRSLittleEndian(&priv.PublicKey, e, sig[:63]) {
+		t.Error("VerifyRSLittleEndian accepted a 63 byte signature")
+	}
+
+	// Short values are padded before reversing, so their zero bytes come
+	// last.
+	got := appendRSLittleEndian(nil, big.NewInt(0x0102), big.NewInt(3))
+	want = make([]byte, 64)
+	want[0], want[1], want[32] = 0x02, 0x01, 0x03
+	if !bytes.Equal(got, want) {
+		t.Errorf("appendRSLittleEndian(0x0102, 3) = %X, want %X", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+package sm2
+
+// AggregateVerify checks a set of attestations over one message, where
+// sigs[i] is an ASN.1 DER signature by pubs[i] produced with SignWithHash
+// using the default uid and no pre-hash. Each distinct public key counts at
+// most once, however many times it appears, so a single signer cannot meet
+// the threshold by repeating itself. It returns the number of distinct
+// signers with a valid signature and whether that number reaches threshold.
+// A threshold below one or an empty set of keys never succeeds, so a
+// message nobody signed is never accepted.
+func AggregateVerify(pubs []*PublicKey, msg []byte, sigs [][]byte, threshold int) (valid int, ok bool) {
+	if len(pubs) == 0 || len(pubs) != len(sigs) || threshold < 1 {
+		return 0, false
+	}
+	seen := make(map[string]bool, len(pubs))
+	for i, pub := range pubs {
+		enc, err := pub.Marshal()
+		if err != nil {
+			continue
+		}
+		id := string(enc)
+		if seen[id] {
+			continue
+		}
+		r, s, err := parseSignature(sigs[i])
+		if err != nil {
+			continue
+		}
+		if VerifyWithHash(pub, msg, nil, 0, r, s) {
+			seen[id] = true
+			valid++
+		}
+	}
+	return valid, valid >= threshold
+}
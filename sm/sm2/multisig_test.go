@@ -0,0 +1,68 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"testing"
+)
+
+func TestAggregateVerify(t *testing.T) {
+	const n, m = 7, 4
+	msg := []byte("block 1234 is final")
+	pubs := make([]*PublicKey, n)
+	sigs := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		priv, err := GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs[i] = &priv.PublicKey
+		signed := msg
+		if i >= m {
+			signed = []byte("block 1234 is not final")
+		}
+		r, s, err := SignWithHash(rand.Reader, priv, signed, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs[i], _ = asn1.Marshal(sm2Signature{r, s})
+	}
+
+	if valid, ok := AggregateVerify(pubs, msg, sigs, m); valid != m || !ok {
+		t.Errorf("AggregateVerify = (%d, %v), want (%d, true)", valid, ok, m)
+	}
+	if valid, ok := AggregateVerify(pubs, msg, sigs, m+1); valid != m || ok {
+		t.Errorf("AggregateVerify with threshold %d = (%d, %v), want (%d, false)", m+1, valid, ok, m)
+	}
+
+	// Repeating one valid signer must not raise the count.
+	dupPubs := []*PublicKey{pubs[0], pubs[0], pubs[0], pubs[1]}
+	dupSigs := [][]byte{sigs[0], sigs[0], sigs[0], sigs[1]}
+	if valid, ok := AggregateVerify(dupPubs, msg, dupSigs, 3); valid != 2 || ok {
+		t.Errorf("AggregateVerify with duplicates = (%d, %v), want (2, false)", valid, ok)
+	}
+
+	// A malformed signature, a nil key and keys with a nil curve or
+	// coordinates are skipped.
+	junkPubs := []*PublicKey{pubs[0], nil, pubs[1], {X: pubs[1].X, Y: pubs[1].Y}, {Curve: P256Sm2()}}
+	junkSigs := [][]byte{{0x30, 0x00}, sigs[1], append(append([]byte{}, sigs[1]...), 0), sigs[1], sigs[1]}
+	if valid, _ := AggregateVerify(junkPubs, msg, junkSigs, 1); valid != 0 {
+		t.Errorf("AggregateVerify counted %d malformed entries as valid", valid)
+	}
+	if _, ok := AggregateVerify(pubs, msg, sigs[:n-1], 1); ok {
+		t.Error("AggregateVerify accepted mismatched key and signature counts")
+	}
+
+	// A threshold below one or no keys at all must not pass vacuously.
+	for _, threshold := range []int{0, -1} {
+		if _, ok := AggregateVerify(pubs, msg, sigs, threshold); ok {
+			t.Errorf("AggregateVerify accepted threshold %d", threshold)
+		}
+	}
+	if _, ok := AggregateVerify(nil, msg, nil, 0); ok {
+		t.Error("AggregateVerify accepted an empty set of keys with threshold 0")
+	}
+	if _, ok := AggregateVerify([]*PublicKey{}, msg, [][]byte{}, 1); ok {
+		t.Error("AggregateVerify accepted an empty set of keys")
+	}
+}
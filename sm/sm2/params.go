@@ -0,0 +1,44 @@
+package sm2
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DumpParams returns the domain parameters of P256Sm2, one per line in the
+// order of GB/T 32918.5, for comparison with the published sm2p256v1
+// values:
+//
+//	p  = FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF
+//	a  = ...
+//	b  = ...
+//	n  = ...
+//	Gx = ...
+//	Gy = ...
+//	h  = 1
+//
+// Each value is upper case hex; p, a, b, n, Gx and Gy are padded to 64
+// digits. a is derived as p - 3, which is what the curve arithmetic
+// assumes.
+func DumpParams() string {
+	params := P256Sm2().Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+	var b strings.Builder
+	for _, v := range []struct {
+		name  string
+		value *big.Int
+	}{
+		{"p", params.P},
+		{"a", a},
+		{"b", params.B},
+		{"n", params.N},
+		{"Gx", params.Gx},
+		{"Gy", params.Gy},
+	} {
+		fmt.Fprintf(&b, "%-2s = %064X\n", v.name, v.value)
+	}
+	// The SM2 curve has prime order, so the cofactor is 1.
+	b.WriteString("h  = 1\n")
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package sm2
+
+import "testing"
+
+func TestDumpParams(t *testing.T) {
+	// sm2p256v1 as published in GB/T 32918.5-2017, section 5.
+	const want = `p  = FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF
+a  = FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFC
+b  = 28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93
+n  = FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123
+Gx = 32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7
+Gy = BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0
+h  = 1
+`
+	if got := DumpParams(); got != want {
+		t.Errorf("DumpParams() =\n%s\nwant\n%s", got, want)
+	}
+}
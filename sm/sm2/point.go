@@ -0,0 +1,252 @@
+package sm2
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+var (
+	errPointInfinity = errors.New("sm2: point at infinity")
+	errPointPrefix   = errors.New("sm2: unknown point encoding")
+	errPointInvalid  = errors.New("sm2: invalid point")
+	errCurveMismatch = errors.New("sm2: public key is not on the SM2 curve")
+)
+
+// isIdentity reports whether (x, y) is the point at infinity, which the
+// elliptic package represents as (0, 0).
+func isIdentity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Sign() == 0
+}
+
+// isSM2Curve reports whether c has the domain parameters of P256Sm2, so
+// that a key carrying an equivalent CurveParams value is accepted as well as
+// one on P256Sm2 itself.
+func isSM2Curve(c elliptic.Curve) bool {
+	if c == nil {
+		return false
+	}
+	p, want := c.Params(), P256Sm2().Params()
+	if p == want {
+		return true
+	}
+	return p != nil && p.BitSize == want.BitSize &&
+		p.P.Cmp(want.P) == 0 && p.N.Cmp(want.N) == 0 && p.B.Cmp(want.B) == 0 &&
+		p.Gx.Cmp(want.Gx) == 0 && p.Gy.Cmp(want.Gy) == 0
+}
+
+// validate checks that pub is a point on its curve other than the identity.
+func (pub *PublicKey) validate() error {
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return errPointInvalid
+	}
+	if isIdentity(pub.X, pub.Y) {
+		return errPointInfinity
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return errPointInvalid
+	}
+	return nil
+}
+
+// Marshal returns the uncompressed encoding of pub, 0x04 || X || Y. It
+// returns an error for the point at infinity and for points that are not
+// on the curve, which have no valid encoding as a public key.
+func (pub *PublicKey) Marshal() ([]byte, error) {
+	if err := pub.validate(); err != nil {
+		return nil, err
+	}
+	return elliptic.Marshal(pub.Curve, pub.X, pub.Y), nil
+}
+
+// Negate returns the public key -pub, i.e. the point (X, P-Y).
+// It returns nil if pub is the identity or not a point on its curve,
+// including a key with a nil curve or coordinate.
+func (pub *PublicKey) Negate() *PublicKey {
+	if pub.validate() != nil {
+		return nil
+	}
+	p := pub.Curve.Params().P
+	y := new(big.Int).Sub(p, pub.Y)
+	y.Mod(y, p)
+	neg := &PublicKey{
+		Curve: pub.Curve,
+		X:     new(big.Int).Set(pub.X),
+		Y:     y,
+	}
+	if !neg.Curve.IsOnCurve(neg.X, neg.Y) {
+		return nil
+	}
+	return neg
+}
+
+// ParsePoint parses an SM2 public key encoded either in compressed form
+// (33 bytes, prefix 0x02 or 0x03) or uncompressed form (65 bytes, prefix
+// 0x04), as described in SEC 1, section 2.3.3. The encoding of the point at
+// infinity (a single 0x00 byte) is rejected.
+func ParsePoint(data []byte) (*PublicKey, error) {
+	if len(data) == 0 {
+		return nil, errPointInvalid
+	}
+	c := P256Sm2()
+	byteLen := (c.Params().BitSize + 7) / 8
+	var x, y *big.Int
+	switch data[0] {
+	case 0x00:
+		return nil, errPointInfinity
+	case 0x02, 0x03:
+		if len(data) != 1+byteLen {
+			return nil, errPointInvalid
+		}
+		x, y = decompressPoint(c, data[1:], uint(data[0]&1))
+	case 0x04:
+		if len(data) != 1+2*byteLen {
+			return nil, errPointInvalid
+		}
+		x, y = elliptic.Unmarshal(c, data)
+	default:
+		return nil, errPointPrefix
+	}
+	if x == nil {
+		return nil, errPointInvalid
+	}
+	return &PublicKey{Curve: c, X: x, Y: y}, nil
+}
+
+// MarshalPoint returns the uncompressed encoding 0x04 || X || Y of (x, y)
+// on the SM2 curve, the same bytes as elliptic.Marshal, with each
+// coordinate padded to 32 bytes. Unlike elliptic.Marshal it returns an
+// error for nil coordinates, coordinates outside [0, P), the identity and
+// points that are not on the curve.
+func MarshalPoint(x, y *big.Int) ([]byte, error) {
+	if err := checkPoint(x, y); err != nil {
+		return nil, err
+	}
+	return elliptic.Marshal(P256Sm2(), x, y), nil
+}
+
+// UnmarshalPoint parses an uncompressed SM2 point as produced by
+// MarshalPoint or elliptic.Marshal. The encoding must be exactly 65 bytes
+// and the point must lie on the SM2 curve and not be the identity; use
+// ParsePoint to also accept compressed points.
+func UnmarshalPoint(data []byte) (x, y *big.Int, err error) {
+	if len(data) != 1+2*32 {
+		return nil, nil, errPointInvalid
+	}
+	if data[0] != 4 {
+		return nil, nil, errPointPrefix
+	}
+	x = new(big.Int).SetBytes(data[1:33])
+	y = new(big.Int).SetBytes(data[33:])
+	if err := checkPoint(x, y); err != nil {
+		return nil, nil, err
+	}
+	return x, y, nil
+}
+
+// checkPoint reports whether (x, y) is a valid non-identity point of the
+// SM2 curve with reduced coordinates.
+func checkPoint(x, y *big.Int) error {
+	if x == nil || y == nil {
+		return errPointInvalid
+	}
+	c := P256Sm2()
+	p := c.Params().P
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(p) >= 0 || y.Cmp(p) >= 0 {
+		return errPointInvalid
+	}
+	if isIdentity(x, y) {
+		return errPointInfinity
+	}
+	if !c.IsOnCurve(x, y) {
+		return errPointInvalid
+	}
+	return nil
+}
+
+// compressPoint returns the 33 byte SEC 1 compressed encoding of (x, y).
+func compressPoint(x, y *big.Int) []byte {
+	out := make([]byte, 33)
+	out[0] = byte(2 + y.Bit(0))
+	x.FillBytes(out[1:])
+	return out
+}
+
+// decompressPoint recovers the point with x-coordinate xBytes whose
+// y-coordinate has the given parity bit. It returns nil if there is no such
+// point on the curve.
+func decompressPoint(c elliptic.Curve, xBytes []byte, ybit uint) (x, y *big.Int) {
+	params := c.Params()
+	x = new(big.Int).SetBytes(xBytes)
+	if x.Cmp(params.P) >= 0 {
+		return nil, nil
+	}
+	// y² = x³ - 3x + b
+	y2 := new(big.Int).Mul(x, x)
+	y2.Mul(y2, x)
+	threeX := new(big.Int).Lsh(x, 1)
+	threeX.Add(threeX, x)
+	y2.Sub(y2, threeX)
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+
+	y = new(big.Int).ModSqrt(y2, params.P)
+	if y == nil {
+		return nil, nil
+	}
+	if y.Bit(0) != ybit {
+		y.Sub(params.P, y)
+	}
+	if !c.IsOnCurve(x, y) {
+		return nil, nil
+	}
+	return x, y
+}
+
+// Blind returns the public key r·pub, for r in [1, N-1]. If the private key
+// of pub is d, the private key of the result is r·d mod N. Blind returns nil
+// if r is out of range or pub is the identity or not a point on its curve,
+// including a key with a nil curve or coordinate.
+func (pub *PublicKey) Blind(r *big.Int) *PublicKey {
+	return pub.scalarMult(r, false)
+}
+
+// Unblind reverses Blind, returning r⁻¹·pub. It returns nil under the same
+// conditions as Blind.
+func (pub *PublicKey) Unblind(r *big.Int) *PublicKey {
+	return pub.scalarMult(r, true)
+}
+
+func (pub *PublicKey) scalarMult(r *big.Int, invert bool) *PublicKey {
+	if pub.validate() != nil {
+		return nil
+	}
+	n := pub.Curve.Params().N
+	if r == nil || r.Sign() <= 0 || r.Cmp(n) >= 0 {
+		return nil
+	}
+	k := r
+	if invert {
+		k = new(big.Int).ModInverse(r, n)
+	}
+	x, y := pub.Curve.ScalarMult(pub.X, pub.Y, k.Bytes())
+	if isIdentity(x, y) || !pub.Curve.IsOnCurve(x, y) {
+		return nil
+	}
+	return &PublicKey{Curve: pub.Curve, X: x, Y: y}
+}
+
+// CheckOrder verifies that pub is a point on its curve, other than the
+// identity, whose order divides N, i.e. N·pub is the identity. The SM2 curve
+// has cofactor 1, so every valid point passes; the check guards against keys
+// that were built by hand or imported without validation.
+func (pub *PublicKey) CheckOrder() error {
+	if err := pub.validate(); err != nil {
+		return err
+	}
+	x, y := pub.Curve.ScalarMult(pub.X, pub.Y, pub.Curve.Params().N.Bytes())
+	if !isIdentity(x, y) {
+		return errors.New("sm2: public key does not have order N")
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func TestNegate(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	neg := pub.Negate()
+	if neg == nil {
+		t.Fatal("Negate returned nil for a valid key")
+	}
+	if !neg.Curve.IsOnCurve(neg.X, neg.Y) {
+		t.Fatal("negated point is not on the curve")
+	}
+	x, y := pub.Curve.Add(pub.X, pub.Y, neg.X, neg.Y)
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("P + (-P) = (%x, %x), want identity", x, y)
+	}
+
+	bad := &PublicKey{Curve: P256Sm2(), X: big.NewInt(1), Y: big.NewInt(1)}
+	if bad.Negate() != nil {
+		t.Error("Negate accepted a point that is not on the curve")
+	}
+	for _, k := range []*PublicKey{{Curve: P256Sm2()}, {Curve: P256Sm2(), X: pub.X}, {X: pub.X, Y: pub.Y}} {
+		if k.Negate() != nil {
+			t.Errorf("Negate accepted a key with a nil field: %+v", k)
+		}
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+
+	uncompressed := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+	compressed := make([]byte, 33)
+	compressed[0] = byte(2 + pub.Y.Bit(0))
+	pub.X.FillBytes(compressed[1:])
+
+	for _, data := range [][]byte{uncompressed, compressed} {
+		got, err := ParsePoint(data)
+		if err != nil {
+			t.Fatalf("ParsePoint(%x): %v", data, err)
+		}
+		if got.X.Cmp(pub.X) != 0 || got.Y.Cmp(pub.Y) != 0 {
+			t.Errorf("ParsePoint(%x) = (%x, %x), want (%x, %x)", data, got.X, got.Y, pub.X, pub.Y)
+		}
+	}
+
+	flipped := append([]byte{}, compressed...)
+	flipped[0] ^= 1
+	got, err := ParsePoint(flipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Y.Cmp(pub.Negate().Y) != 0 {
+		t.Error("compressed point with the other parity did not decode to -P")
+	}
+
+	bad := [][]byte{
+		nil,
+		{0x00},
+		append([]byte{0x05}, compressed[1:]...),
+		append([]byte{0x06}, uncompressed[1:]...),
+		compressed[:32],
+		uncompressed[:64],
+	}
+	offCurve := append([]byte{}, uncompressed...)
+	offCurve[64] ^= 1
+	bad = append(bad, offCurve)
+	for _, data := range bad {
+		if _, err := ParsePoint(data); err == nil {
+			t.Errorf("ParsePoint(%x) succeeded, want error", data)
+		}
+	}
+}
+
+func TestBlind(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	n := pub.Curve.Params().N
+	r, err := randFieldElement(pub.Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blinded := pub.Blind(r)
+	if blinded == nil {
+		t.Fatal("Blind returned nil")
+	}
+	// The blinded key belongs to the private key r·d.
+	rd := new(big.Int).Mul(r, priv.D)
+	rd.Mod(rd, n)
+	x, y := pub.Curve.ScalarBaseMult(rd.Bytes())
+	if blinded.X.Cmp(x) != 0 || blinded.Y.Cmp(y) != 0 {
+		t.Error("r·pub does not equal (r·d)·G")
+	}
+
+	unblinded := blinded.Unblind(r)
+	if unblinded == nil || unblinded.X.Cmp(pub.X) != 0 || unblinded.Y.Cmp(pub.Y) != 0 {
+		t.Error("Unblind(Blind(pub, r), r) != pub")
+	}
+
+	for _, bad := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1), n, new(big.Int).Add(n, one)} {
+		if pub.Blind(bad) != nil {
+			t.Errorf("Blind accepted out of range scalar %v", bad)
+		}
+		if pub.Unblind(bad) != nil {
+			t.Errorf("Unblind accepted out of range scalar %v", bad)
+		}
+	}
+	offCurve := &PublicKey{Curve: pub.Curve, X: big.NewInt(1), Y: big.NewInt(1)}
+	if offCurve.Blind(r) != nil {
+		t.Error("Blind accepted a point that is not on the curve")
+	}
+	for _, k := range []*PublicKey{{Curve: pub.Curve}, {Curve: pub.Curve, Y: pub.Y}, {X: pub.X, Y: pub.Y}} {
+		if k.Blind(r) != nil || k.Unblind(r) != nil {
+			t.Errorf("Blind or Unblind accepted a key with a nil field: %+v", k)
+		}
+	}
+}
+
+// toyCurve is the curve y² = x³ - 3x + 5 over GF(11), whose group is cyclic
+// of order 15. N is set to 5, the order of the base point (3, 1).
+func toyCurve() *elliptic.CurveParams {
+	return &elliptic.CurveParams{
+		Name:    "toy",
+		P:       big.NewInt(11),
+		N:       big.NewInt(5),
+		B:       big.NewInt(5),
+		Gx:      big.NewInt(3),
+		Gy:      big.NewInt(1),
+		BitSize: 4,
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := priv.PublicKey.CheckOrder(); err != nil {
+		t.Errorf("CheckOrder on a generated key: %v", err)
+	}
+
+	c := P256Sm2()
+	for _, tc := range []struct {
+		pub  *PublicKey
+		want error
+	}{
+		{&PublicKey{Curve: c, X: new(big.Int), Y: new(big.Int)}, errPointInfinity},
+		{&PublicKey{Curve: c, X: big.NewInt(1), Y: big.NewInt(1)}, errPointInvalid},
+		{&PublicKey{Curve: c, X: big.NewInt(1)}, errPointInvalid},
+	} {
+		if err := tc.pub.CheckOrder(); err != tc.want {
+			t.Errorf("CheckOrder(%v, %v): err = %v, want %v", tc.pub.X, tc.pub.Y, err, tc.want)
+		}
+	}
+
+	toy := toyCurve()
+	if err := (&PublicKey{Curve: toy, X: toy.Gx, Y: toy.Gy}).CheckOrder(); err != nil {
+		t.Errorf("CheckOrder on the toy base point: %v", err)
+	}
+	// (5, 4) has order 3 on the toy curve, which does not divide N = 5.
+	if !toy.IsOnCurve(big.NewInt(5), big.NewInt(4)) {
+		t.Fatal("toy point is not on the toy curve")
+	}
+	offOrder := &PublicKey{Curve: toy, X: big.NewInt(5), Y: big.NewInt(4)}
+	if err := offOrder.CheckOrder(); err == nil {
+		t.Error("CheckOrder accepted a point whose order does not divide N")
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := priv.Curve
+	identity := &PublicKey{Curve: c, X: new(big.Int), Y: new(big.Int)}
+
+	if _, err := identity.Marshal(); err != errPointInfinity {
+		t.Errorf("Marshal(identity): err = %v, want %v", err, errPointInfinity)
+	}
+	if b, err := priv.PublicKey.Marshal(); err != nil || !bytes.Equal(b, elliptic.Marshal(c, priv.X, priv.Y)) {
+		t.Errorf("Marshal of a valid key = %x, %v", b, err)
+	}
+	if identity.Negate() != nil {
+		t.Error("Negate(identity) != nil")
+	}
+	if identity.Blind(big.NewInt(2)) != nil {
+		t.Error("Blind(identity) != nil")
+	}
+	if _, _, err := priv.SharedPoint(identity); err == nil {
+		t.Error("SharedPoint accepted the identity")
+	}
+	if _, err := Encrypt(rand.Reader, identity, []byte("x")); err == nil {
+		t.Error("Encrypt accepted the identity as a public key")
+	}
+
+	hashed := sm3.SumSM3([]byte("identity"))
+	r, s, err := Sign(rand.Reader, priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Verify(identity, hashed[:], r, s) {
+		t.Error("Verify succeeded with the identity as public key")
+	}
+
+	// With P = G the verifier computes s·G + (r + s)·G, which is the
+	// identity for s = 1 and r = N - 2.
+	g := &PublicKey{Curve: c, X: c.Params().Gx, Y: c.Params().Gy}
+	r = new(big.Int).Sub(c.Params().N, big.NewInt(2))
+	if Verify(g, hashed[:], r, big.NewInt(1)) {
+		t.Error("Verify succeeded with an identity intermediate")
+	}
+}
+
+func TestMarshalPoint(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	c := priv.Curve
+	data, err := MarshalPoint(priv.X, priv.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, elliptic.Marshal(c, priv.X, priv.Y)) {
+		t.Error("MarshalPoint differs from elliptic.Marshal")
+	}
+	x, y, err := UnmarshalPoint(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Cmp(priv.X) != 0 || y.Cmp(priv.Y) != 0 {
+		t.Error("UnmarshalPoint did not round-trip")
+	}
+
+	// A small x still encodes to the full 32 bytes. Adding P to it gives
+	// an unreduced coordinate that still fits those 32 bytes.
+	one := big.NewInt(1)
+	smallX := big.NewInt(1)
+	var smallY *big.Int
+	for ; smallY == nil; smallX.Add(smallX, one) {
+		_, smallY = decompressPoint(c, smallX.FillBytes(make([]byte, 32)), 0)
+	}
+	smallX.Sub(smallX, one)
+	if small, err := MarshalPoint(smallX, smallY); err != nil || len(small) != 65 {
+		t.Fatalf("MarshalPoint of a small point = %x, %v", small, err)
+	}
+	unreduced := make([]byte, 65)
+	unreduced[0] = 4
+	new(big.Int).Add(smallX, c.Params().P).FillBytes(unreduced[1:33])
+	smallY.FillBytes(unreduced[33:])
+
+	offCurve := append([]byte{}, data...)
+	offCurve[64] ^= 1
+	for name, enc := range map[string][]byte{
+		"off curve":      offCurve,
+		"identity":       append([]byte{4}, make([]byte, 64)...),
+		"compressed":     compressPoint(priv.X, priv.Y),
+		"wrong prefix":   append([]byte{5}, data[1:]...),
+		"truncated":      data[:64],
+		"unreduced x":    unreduced,
+		"trailing bytes": append(append([]byte{}, data...), 0),
+	} {
+		if _, _, err := UnmarshalPoint(enc); err == nil {
+			t.Errorf("%s: UnmarshalPoint accepted %x", name, enc)
+		}
+	}
+	if _, _, err := UnmarshalPoint(append([]byte{4}, make([]byte, 64)...)); err != errPointInfinity {
+		t.Errorf("UnmarshalPoint(identity): err = %v, want %v", err, errPointInfinity)
+	}
+	if _, err := MarshalPoint(priv.X, new(big.Int).Add(priv.Y, one)); err == nil {
+		t.Error("MarshalPoint accepted an off-curve point")
+	}
+	if _, err := MarshalPoint(nil, priv.Y); err == nil {
+		t.Error("MarshalPoint accepted a nil coordinate")
+	}
+}
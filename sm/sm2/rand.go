@@ -0,0 +1,44 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"io"
+	"sync/atomic"
+)
+
+// defaultRand holds a randSource. atomic.Value requires every stored value
+// to have the same concrete type, so the reader is wrapped.
+var defaultRand atomic.Value
+
+type randSource struct{ r io.Reader }
+
+func init() {
+	defaultRand.Store(randSource{rand.Reader})
+}
+
+// DefaultRand returns the source of randomness used by every function in
+// this package that takes a rand argument when that argument is nil. It is
+// crypto/rand.Reader unless SetDefaultRand has replaced it.
+func DefaultRand() io.Reader {
+	return defaultRand.Load().(randSource).r
+}
+
+// SetDefaultRand replaces the reader returned by DefaultRand, affecting
+// all later calls from every goroutine; a nil r restores
+// crypto/rand.Reader. It is safe to call concurrently with functions that
+// use DefaultRand, but a call already in progress may finish with the old
+// reader. The reader itself must be safe for concurrent use.
+func SetDefaultRand(r io.Reader) {
+	if r == nil {
+		r = rand.Reader
+	}
+	defaultRand.Store(randSource{r})
+}
+
+// randReader returns r, or DefaultRand() if r is nil.
+func randReader(r io.Reader) io.Reader {
+	if r == nil {
+		return DefaultRand()
+	}
+	return r
+}
@@ -0,0 +1,72 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func TestNilRand(t *testing.T) {
+	priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Curve.IsOnCurve(priv.X, priv.Y) {
+		t.Fatal("GenerateKey(nil) returned a key that is not on the curve")
+	}
+
+	hashed := sm3.SumSM3([]byte("nil rand"))
+	r, s, err := Sign(nil, priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(&priv.PublicKey, hashed[:], r, s) {
+		t.Error("Sign(nil, ...) produced a signature that does not verify")
+	}
+
+	msg := []byte("nil rand")
+	for _, enc := range []func() ([]byte, error){
+		func() ([]byte, error) { return Encrypt(nil, &priv.PublicKey, msg) },
+		func() ([]byte, error) { return EncryptLarge(nil, &priv.PublicKey, msg) },
+	} {
+		if _, err := enc(); err != nil {
+			t.Errorf("encryption with nil rand: %v", err)
+		}
+	}
+	if _, err := RandScalar(nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDefaultRand(t *testing.T) {
+	defer SetDefaultRand(nil)
+
+	// Two keys drawn from the same replayed source through DefaultRand
+	// must be equal.
+	seed := bytes.Repeat([]byte{0x5a}, 40)
+	SetDefaultRand(bytes.NewReader(seed))
+	a, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetDefaultRand(bytes.NewReader(seed))
+	b, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.D.Cmp(b.D) != 0 {
+		t.Error("GenerateKey(nil) did not read from DefaultRand")
+	}
+
+	SetDefaultRand(errReader{})
+	if _, err := GenerateKey(nil); err == nil {
+		t.Error("GenerateKey(nil) ignored a failing DefaultRand")
+	}
+
+	SetDefaultRand(nil)
+	if DefaultRand() != rand.Reader {
+		t.Error("SetDefaultRand(nil) did not restore crypto/rand.Reader")
+	}
+}
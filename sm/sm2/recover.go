@@ -0,0 +1,117 @@
+package sm2
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// RecoverableSignatureSize is the length of the binary encoding of a
+// RecoverableSignature: r and s as 32 byte big-endian integers followed by
+// the recovery id.
+const RecoverableSignatureSize = 65
+
+// RecoverableSignature is an SM2 signature together with a recovery id that
+// identifies, among the candidate points R = k·G consistent with r, the one
+// used during signing. Bit 0 of RecID is the parity of the y-coordinate of
+// R, bit 1 is set if the x-coordinate of R was reduced modulo N.
+//
+// The public key can only be recovered from a digest that does not itself
+// depend on the key. This rules out e = SM3(Z_A || M) as computed by
+// SignWithHash, since Z_A is derived from the public key; callers must sign
+// and recover with a key-independent digest such as SM3(M).
+type RecoverableSignature struct {
+	R, S  *big.Int
+	RecID byte
+}
+
+var errRecoverableSignature = errors.New("sm2: invalid recoverable signature")
+
+// SignRecoverable signs hash like Sign and returns the signature together
+// with its recovery id.
+func SignRecoverable(rand io.Reader, priv *PrivateKey, hash []byte) (*RecoverableSignature, error) {
+	r, s, err := Sign(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	c := priv.Curve
+	n := c.Params().N
+	// R = k·G = s·G + (r + s)·P, the point the verifier reconstructs.
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+	x1, y1 := c.ScalarMult(priv.X, priv.Y, t.Bytes())
+	x2, y2 := c.ScalarBaseMult(s.Bytes())
+	x, y := c.Add(x1, y1, x2, y2)
+
+	sig := &RecoverableSignature{R: r, S: s, RecID: byte(y.Bit(0))}
+	if x.Cmp(n) >= 0 {
+		sig.RecID |= 2
+	}
+	return sig, nil
+}
+
+// RecoverPublicKey returns the public key that produced sig over hash,
+// where hash is the digest that was passed to SignRecoverable.
+func (sig *RecoverableSignature) RecoverPublicKey(hash []byte) (*PublicKey, error) {
+	e, err := hashToInt(hash)
+	if err != nil {
+		return nil, err
+	}
+	c := P256Sm2()
+	params := c.Params()
+	n := params.N
+	r, s := sig.R, sig.S
+	if r == nil || s == nil || sig.RecID > 3 ||
+		r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return nil, errRecoverableSignature
+	}
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return nil, errRecoverableSignature
+	}
+
+	// x1 = (r - e) mod N, plus N if the signer's x-coordinate was reduced.
+	x := new(big.Int).Sub(r, e)
+	x.Mod(x, n)
+	if sig.RecID&2 != 0 {
+		x.Add(x, n)
+	}
+	x, y := decompressPoint(c, fieldBytes(x), uint(sig.RecID&1))
+	if x == nil {
+		return nil, errRecoverableSignature
+	}
+
+	// P = (r + s)⁻¹·(R - s·G)
+	sx, sy := c.ScalarBaseMult(s.Bytes())
+	sy.Sub(params.P, sy)
+	px, py := c.Add(x, y, sx, sy)
+	px, py = c.ScalarMult(px, py, fieldForOrder(n).inverse(t).Bytes())
+	pub := &PublicKey{Curve: c, X: px, Y: py}
+	if pub.validate() != nil {
+		return nil, errRecoverableSignature
+	}
+	return pub, nil
+}
+
+// MarshalBinary encodes sig as r || s || recid, RecoverableSignatureSize
+// bytes in total.
+func (sig *RecoverableSignature) MarshalBinary() ([]byte, error) {
+	if sig.R == nil || sig.S == nil || sig.R.Sign() < 0 || sig.S.Sign() < 0 ||
+		sig.R.BitLen() > 256 || sig.S.BitLen() > 256 || sig.RecID > 3 {
+		return nil, errRecoverableSignature
+	}
+	out := appendRS(make([]byte, 0, RecoverableSignatureSize), sig.R, sig.S)
+	return append(out, sig.RecID), nil
+}
+
+// UnmarshalBinary decodes the form produced by MarshalBinary.
+func (sig *RecoverableSignature) UnmarshalBinary(data []byte) error {
+	if len(data) != RecoverableSignatureSize || data[64] > 3 {
+		return errRecoverableSignature
+	}
+	sig.R = new(big.Int).SetBytes(data[:32])
+	sig.S = new(big.Int).SetBytes(data[32:64])
+	sig.RecID = data[64]
+	return nil
+}
@@ -0,0 +1,62 @@
+package sm2
+
+import (
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func TestRecoverableSignature(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		priv, err := GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := sm3.SumSM3([]byte("on-chain message"))
+		sig, err := SignRecoverable(nil, priv, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(&priv.PublicKey, hashed[:], sig.R, sig.S) {
+			t.Fatal("SignRecoverable produced a signature that does not verify")
+		}
+
+		data, err := sig.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != RecoverableSignatureSize {
+			t.Fatalf("MarshalBinary returned %d bytes", len(data))
+		}
+		var decoded RecoverableSignature
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.R.Cmp(sig.R) != 0 || decoded.S.Cmp(sig.S) != 0 || decoded.RecID != sig.RecID {
+			t.Fatal("UnmarshalBinary(MarshalBinary(sig)) != sig")
+		}
+
+		pub, err := decoded.RecoverPublicKey(hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+			t.Fatal("recovered the wrong public key")
+		}
+
+		decoded.RecID ^= 1
+		if pub, err := decoded.RecoverPublicKey(hashed[:]); err == nil && pub.X.Cmp(priv.X) == 0 {
+			t.Fatal("flipped recovery id recovered the signer's key")
+		}
+	}
+
+	var sig RecoverableSignature
+	if err := sig.UnmarshalBinary(make([]byte, 64)); err == nil {
+		t.Error("UnmarshalBinary accepted 64 bytes")
+	}
+	bad := make([]byte, RecoverableSignatureSize)
+	bad[64] = 4
+	if err := sig.UnmarshalBinary(bad); err == nil {
+		t.Error("UnmarshalBinary accepted recovery id 4")
+	}
+}
@@ -0,0 +1,151 @@
+package sm2
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// montField implements constant-time Montgomery arithmetic modulo an odd
+// 256-bit modulus, held as four little-endian 64-bit limbs. It backs the
+// inversion of secret scalars in Sign, where big.Int.ModInverse would leak
+// timing information about its input.
+type montField struct {
+	n     [4]uint64
+	n0inv uint64    // -n⁻¹ mod 2⁶⁴
+	rr    [4]uint64 // R² mod n, R = 2²⁵⁶
+	one   [4]uint64 // R mod n
+	exp   []byte    // n - 2, big-endian
+}
+
+var (
+	orderFieldOnce sync.Once
+	orderField     *montField
+)
+
+func newMontField(n *big.Int) *montField {
+	f := new(montField)
+	f.n = toLimbs(n)
+
+	// Newton iteration for n⁻¹ mod 2⁶⁴; each step doubles the correct bits.
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - f.n[0]*inv
+	}
+	f.n0inv = -inv
+
+	r := new(big.Int).Lsh(one, 256)
+	f.one = toLimbs(new(big.Int).Mod(r, n))
+	f.rr = toLimbs(new(big.Int).Mod(new(big.Int).Mul(r, r), n))
+	f.exp = new(big.Int).Sub(n, big.NewInt(2)).Bytes()
+	return f
+}
+
+// fieldForOrder returns the Montgomery field for the curve order n,
+// reusing the precomputed SM2 one when n is the SM2 order.
+func fieldForOrder(n *big.Int) *montField {
+	orderFieldOnce.Do(func() {
+		orderField = newMontField(P256Sm2().Params().N)
+	})
+	if n.Cmp(P256Sm2().Params().N) == 0 {
+		return orderField
+	}
+	return newMontField(n)
+}
+
+// inverse returns x⁻¹ mod n computed as x^(n-2) by Fermat's little theorem,
+// for n prime and 0 <= x < n. The sequence of operations depends only on
+// n, not on x. For x = 0 it returns 0.
+func (f *montField) inverse(x *big.Int) *big.Int {
+	xl := toLimbs(x)
+	var xm, acc [4]uint64
+	f.mul(&xm, &xl, &f.rr)
+	f.invertMont(&acc, &xm)
+	var out [4]uint64
+	f.mul(&out, &acc, &[4]uint64{1})
+	return fromLimbs(&out)
+}
+
+// invertMont sets z = x⁻¹ for x in Montgomery form, as x^(n-2). z may
+// alias x.
+func (f *montField) invertMont(z, x *[4]uint64) {
+	xm := *x
+	acc := f.one
+	for _, b := range f.exp {
+		for i := 7; i >= 0; i-- {
+			f.mul(&acc, &acc, &acc)
+			if b>>uint(i)&1 == 1 {
+				f.mul(&acc, &acc, &xm)
+			}
+		}
+	}
+	*z = acc
+}
+
+// mul sets z = x * y * R⁻¹ mod n using the CIOS method, with a constant-time
+// final subtraction. z may alias x or y.
+func (f *montField) mul(z, x, y *[4]uint64) {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		var c uint64
+		for j := 0; j < 4; j++ {
+			c, t[j] = madd(x[j], y[i], t[j], c)
+		}
+		var carry uint64
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+
+		m := t[0] * f.n0inv
+		c, _ = madd(m, f.n[0], t[0], 0)
+		for j := 1; j < 4; j++ {
+			c, t[j-1] = madd(m, f.n[j], t[j], c)
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+
+	var d [4]uint64
+	var borrow uint64
+	for j := 0; j < 4; j++ {
+		d[j], borrow = bits.Sub64(t[j], f.n[j], borrow)
+	}
+	// Keep d when t >= n, i.e. when t overflowed 256 bits or the
+	// subtraction did not borrow.
+	mask := -(t[4] | (borrow ^ 1))
+	for j := 0; j < 4; j++ {
+		z[j] = d[j]&mask | t[j]&^mask
+	}
+}
+
+// madd returns the 128-bit result of a*b + c + d as (hi, lo).
+func madd(a, b, c, d uint64) (hi, lo uint64) {
+	hi, lo = bits.Mul64(a, b)
+	var carry uint64
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	lo, carry = bits.Add64(lo, d, 0)
+	hi += carry
+	return
+}
+
+func toLimbs(x *big.Int) [4]uint64 {
+	var buf [32]byte
+	x.FillBytes(buf[:])
+	var l [4]uint64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			l[i] |= uint64(buf[31-8*i-j]) << uint(8*j)
+		}
+	}
+	return l
+}
+
+func fromLimbs(l *[4]uint64) *big.Int {
+	var buf [32]byte
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			buf[31-8*i-j] = byte(l[i] >> uint(8*j))
+		}
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
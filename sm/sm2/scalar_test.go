@@ -0,0 +1,55 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestInverseMatchesModInverse(t *testing.T) {
+	n := P256Sm2().Params().N
+	f := fieldForOrder(n)
+	inputs := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(n, one),
+		new(big.Int).Sub(n, big.NewInt(2)),
+	}
+	for i := 0; i < 64; i++ {
+		x, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.Sign() == 0 {
+			continue
+		}
+		inputs = append(inputs, x)
+	}
+	for _, x := range inputs {
+		want := new(big.Int).ModInverse(x, n)
+		if got := f.inverse(x); got.Cmp(want) != 0 {
+			t.Errorf("inverse(%x) = %x, want %x", x, got, want)
+		}
+	}
+	if got := f.inverse(new(big.Int)); got.Sign() != 0 {
+		t.Errorf("inverse(0) = %x, want 0", got)
+	}
+
+	// A modulus other than the SM2 order goes through a fresh field.
+	p := P256Sm2().Params().P
+	x := big.NewInt(123456789)
+	if got, want := fieldForOrder(p).inverse(x), new(big.Int).ModInverse(x, p); got.Cmp(want) != 0 {
+		t.Errorf("inverse mod P = %x, want %x", got, want)
+	}
+}
+
+func BenchmarkInverse(b *testing.B) {
+	n := P256Sm2().Params().N
+	f := fieldForOrder(n)
+	x, _ := rand.Int(rand.Reader, n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.inverse(x)
+	}
+}
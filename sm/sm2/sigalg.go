@@ -0,0 +1,74 @@
+package sm2
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+)
+
+// Object identifiers for SM2 from GM/T 0006.
+var (
+	OIDNamedCurveSM2       = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+	OIDSignatureSM2WithSM3 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+)
+
+// algSignature is a self-describing signature:
+//
+//	SEQUENCE {
+//	    algorithm  AlgorithmIdentifier, -- signature OID, curve OID as parameters
+//	    signature  SEQUENCE { r INTEGER, s INTEGER }
+//	}
+type algSignature struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Signature sm2Signature
+}
+
+// MarshalSignatureWithAlg encodes (r, s) together with the signature
+// algorithm alg, typically OIDSignatureSM2WithSM3, and the SM2 curve OID,
+// so that the stored signature identifies how it must be verified.
+func MarshalSignatureWithAlg(alg asn1.ObjectIdentifier, r, s *big.Int) ([]byte, error) {
+	if len(alg) == 0 {
+		return nil, errors.New("sm2: missing signature algorithm")
+	}
+	if r == nil || s == nil || r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, errors.New("sm2: invalid signature value")
+	}
+	curve, err := asn1.Marshal(OIDNamedCurveSM2)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(algSignature{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  alg,
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		Signature: sm2Signature{r, s},
+	})
+}
+
+// ParseSignatureWithAlg decodes a signature produced by
+// MarshalSignatureWithAlg, returning its algorithm OID and (r, s). It fails
+// if the envelope names a curve other than SM2.
+func ParseSignatureWithAlg(data []byte) (alg asn1.ObjectIdentifier, r, s *big.Int, err error) {
+	var sig algSignature
+	rest, err := asn1.Unmarshal(data, &sig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(rest) != 0 {
+		return nil, nil, nil, errors.New("sm2: trailing data after signature")
+	}
+	var curve asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(sig.Algorithm.Parameters.FullBytes, &curve); err != nil || len(rest) != 0 {
+		return nil, nil, nil, errors.New("sm2: invalid curve parameters in signature")
+	}
+	if !curve.Equal(OIDNamedCurveSM2) {
+		return nil, nil, nil, errors.New("sm2: signature is not over the SM2 curve")
+	}
+	r, s = sig.Signature.R, sig.Signature.S
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, nil, nil, errors.New("sm2: invalid signature value")
+	}
+	return sig.Algorithm.Algorithm, r, s, nil
+}
@@ -0,0 +1,53 @@
+package sm2
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func TestSignatureWithAlg(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	hashed := sm3.SumSM3([]byte("archived document"))
+	r, s, err := Sign(nil, priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalSignatureWithAlg(OIDSignatureSM2WithSM3, r, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alg, r2, s2, err := ParseSignatureWithAlg(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !alg.Equal(OIDSignatureSM2WithSM3) {
+		t.Errorf("algorithm = %v, want %v", alg, OIDSignatureSM2WithSM3)
+	}
+	if r2.Cmp(r) != 0 || s2.Cmp(s) != 0 {
+		t.Error("ParseSignatureWithAlg returned a different (r, s)")
+	}
+	if !Verify(&priv.PublicKey, hashed[:], r2, s2) {
+		t.Error("parsed signature does not verify")
+	}
+
+	if _, _, _, err := ParseSignatureWithAlg(append(data, 0)); err == nil {
+		t.Error("ParseSignatureWithAlg accepted trailing data")
+	}
+	p256, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+	other, _ := asn1.Marshal(algSignature{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  OIDSignatureSM2WithSM3,
+			Parameters: asn1.RawValue{FullBytes: p256},
+		},
+		Signature: sm2Signature{r, s},
+	})
+	if _, _, _, err := ParseSignatureWithAlg(other); err == nil {
+		t.Error("ParseSignatureWithAlg accepted a P-256 curve OID")
+	}
+	if _, err := MarshalSignatureWithAlg(nil, r, s); err == nil {
+		t.Error("MarshalSignatureWithAlg accepted an empty algorithm")
+	}
+}
@@ -0,0 +1,38 @@
+package sm2
+
+import (
+	"io"
+	"math/big"
+
+	"crypto/sm/sm3"
+)
+
+// A SigningContext signs messages with one private key and identity,
+// computing Z_A once when it is created rather than for every message as
+// SignWithHash does. It is safe for concurrent use, provided the rand
+// passed to Sign is.
+type SigningContext struct {
+	priv *PrivateKey
+	za   []byte
+}
+
+// NewSigningContext returns a SigningContext for priv and uid. An empty
+// uid selects the default, as in ZA.
+func NewSigningContext(priv *PrivateKey, uid []byte) (*SigningContext, error) {
+	za, err := ZA(&priv.PublicKey, uid)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningContext{priv: priv, za: za}, nil
+}
+
+// Digest returns e = SM3(Z_A || msg), the value signed by Sign.
+func (c *SigningContext) Digest(msg []byte) []byte {
+	return sm3.SumSM3Multi(c.za, msg)
+}
+
+// Sign signs msg, producing the same signature as SignWithHash with the
+// context's key and uid, no pre-hash and the same rand.
+func (c *SigningContext) Sign(rand io.Reader, msg []byte) (r, s *big.Int, err error) {
+	return Sign(rand, c.priv, c.Digest(msg))
+}
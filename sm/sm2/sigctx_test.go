@@ -0,0 +1,83 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestSigningContext(t *testing.T) {
+	priv := vectorKey()
+	msg := []byte("message digest")
+	ctx, err := NewSigningContext(priv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s, err := ctx.Sign(scalarReader(vectorK), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Cmp(vectorR) != 0 || s.Cmp(vectorS) != 0 {
+		t.Errorf("signature = (%X, %X), want (%X, %X)", r, s, vectorR, vectorS)
+	}
+
+	uid := []byte("ALICE123@YAHOO.COM")
+	ctx, err = NewSigningContext(priv, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []string{"", "first", "second"} {
+		r, s, err := ctx.Sign(rand.Reader, []byte(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyWithHash(&priv.PublicKey, []byte(m), uid, 0, r, s) {
+			t.Errorf("signature of %q does not verify", m)
+		}
+	}
+
+	if _, err := NewSigningContext(priv, make([]byte, 8192)); err == nil {
+		t.Error("NewSigningContext accepted an 8192 byte uid")
+	}
+}
+
+// The two digest benchmarks isolate the part of signing that
+// SigningContext saves; the scalar multiplication in Sign is the same
+// either way.
+
+func BenchmarkDigestPerMessage(b *testing.B) {
+	priv := vectorKey()
+	msg := make([]byte, 256)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		messageDigest(&priv.PublicKey, msg, nil, 0)
+	}
+}
+
+func BenchmarkDigestSigningContext(b *testing.B) {
+	ctx, _ := NewSigningContext(vectorKey(), nil)
+	msg := make([]byte, 256)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.Digest(msg)
+	}
+}
+
+func BenchmarkSignPerMessage(b *testing.B) {
+	priv := vectorKey()
+	msg := make([]byte, 256)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SignWithHash(rand.Reader, priv, msg, nil, 0)
+	}
+}
+
+func BenchmarkSignSigningContext(b *testing.B) {
+	ctx, _ := NewSigningContext(vectorKey(), nil)
+	msg := make([]byte, 256)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.Sign(rand.Reader, msg)
+	}
+}
@@ -0,0 +1,35 @@
+package sm2
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Signer is a private key held outside this package, such as in a PKCS #11
+// token, that can only perform the scalar step of SM2 signing. Given the
+// integer e = SM3(Z_A || M) it returns (r, s) exactly as SignPrehashedE
+// would with the key, drawing its nonce from its own random source.
+type Signer interface {
+	SignPrehashedE(e *big.Int) (r, s *big.Int, err error)
+}
+
+// SignWithSigner signs msg for the identity uid like SignWithHash with
+// h == 0, computing Z_A and e in Go and delegating only the scalar step to
+// signer. pub must be the public key matching signer. The signature is
+// checked against pub before it is returned, so a token holding a
+// different key, or one that faults, yields an error rather than a bad
+// signature.
+func SignWithSigner(signer Signer, pub *PublicKey, msg, uid []byte) (r, s *big.Int, err error) {
+	e, err := messageDigest(pub, msg, uid, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, s, err = signer.SignPrehashedE(new(big.Int).SetBytes(e))
+	if err != nil {
+		return nil, nil, err
+	}
+	if r == nil || s == nil || !Verify(pub, e, r, s) {
+		return nil, nil, errors.New("sm2: external signer returned a signature that does not verify")
+	}
+	return r, s, nil
+}
@@ -0,0 +1,47 @@
+package sm2
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// softToken stands in for a hardware token: it sees only e.
+type softToken struct {
+	priv  *PrivateKey
+	calls int
+	fail  bool
+}
+
+func (tok *softToken) SignPrehashedE(e *big.Int) (r, s *big.Int, err error) {
+	tok.calls++
+	if tok.fail {
+		return nil, nil, errors.New("token removed")
+	}
+	return SignPrehashedE(nil, tok.priv, e)
+}
+
+func TestSignWithSigner(t *testing.T) {
+	priv, _ := GenerateKey(nil)
+	tok := &softToken{priv: priv}
+	msg, uid := []byte("signed in hardware"), []byte("alice@example.com")
+	r, s, err := SignWithSigner(tok, &priv.PublicKey, msg, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.calls != 1 {
+		t.Errorf("token was called %d times, want 1", tok.calls)
+	}
+	if !VerifyWithHash(&priv.PublicKey, msg, uid, 0, r, s) {
+		t.Error("signature from the token does not verify")
+	}
+
+	other, _ := GenerateKey(nil)
+	if _, _, err := SignWithSigner(tok, &other.PublicKey, msg, uid); err == nil {
+		t.Error("SignWithSigner accepted a token holding a different key")
+	}
+	tok.fail = true
+	if _, _, err := SignWithSigner(tok, &priv.PublicKey, msg, uid); err == nil {
+		t.Error("SignWithSigner hid a token error")
+	}
+}
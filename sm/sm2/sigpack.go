@@ -0,0 +1,65 @@
+package sm2
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// rawSignatureSize is the length of a raw signature, r || s as two 32 byte
+// big-endian integers, the form returned by SignBoth.
+const rawSignatureSize = 64
+
+// appendRS appends the raw form of the signature (r, s) to dst and returns
+// the extended slice. r and s must be non-negative and fit in 32 bytes.
+func appendRS(dst []byte, r, s *big.Int) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, rawSignatureSize)...)
+	r.FillBytes(dst[n : n+32])
+	s.FillBytes(dst[n+32:])
+	return dst
+}
+
+var errMalformedPack = errors.New("sm2: malformed signature pack")
+
+// PackSignatures concatenates raw signatures, each r || s as returned by
+// SignBoth, behind a 4 byte big-endian count:
+//
+//	count || sigs[0] || sigs[1] || ...
+//
+// Every signature must be exactly 64 bytes.
+func PackSignatures(sigs [][]byte) ([]byte, error) {
+	if uint64(len(sigs)) > 0xffffffff {
+		return nil, errors.New("sm2: too many signatures to pack")
+	}
+	out := make([]byte, 4, 4+len(sigs)*rawSignatureSize)
+	binary.BigEndian.PutUint32(out, uint32(len(sigs)))
+	for _, sig := range sigs {
+		if len(sig) != rawSignatureSize {
+			return nil, errors.New("sm2: raw signature must be 64 bytes")
+		}
+		out = append(out, sig...)
+	}
+	return out, nil
+}
+
+// UnpackSignatures splits a pack produced by PackSignatures back into its
+// raw signatures. The length of data must match the count exactly. The
+// signatures are returned as they were packed, without checking that r
+// and s are in range.
+func UnpackSignatures(data []byte) ([][]byte, error) {
+	if len(data) < 4 {
+		return nil, errMalformedPack
+	}
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(len(data)) != uint64(n)*rawSignatureSize {
+		return nil, errMalformedPack
+	}
+	sigs := make([][]byte, n)
+	for i := range sigs {
+		sigs[i] = append([]byte{}, data[:rawSignatureSize]...)
+		data = data[rawSignatureSize:]
+	}
+	return sigs, nil
+}
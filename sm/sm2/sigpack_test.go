@@ -0,0 +1,66 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func TestPackSignatures(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	var sigs [][]byte
+	for n := 0; n <= 5; n++ {
+		packed, err := PackSignatures(sigs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(packed) != 4+64*n {
+			t.Errorf("%d signatures packed into %d bytes", n, len(packed))
+		}
+		got, err := UnpackSignatures(packed)
+		if err != nil {
+			t.Fatalf("%d signatures: %v", n, err)
+		}
+		if len(got) != n {
+			t.Fatalf("unpacked %d signatures, want %d", len(got), n)
+		}
+		for i := range got {
+			if !bytes.Equal(got[i], sigs[i]) {
+				t.Errorf("signature %d of %d = %x, want %x", i, n, got[i], sigs[i])
+			}
+		}
+
+		hash := sm3.SumSM3([]byte{byte(n)})
+		_, raw, err := SignBoth(rand.Reader, priv, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, raw)
+	}
+}
+
+func TestPackSignaturesRejects(t *testing.T) {
+	if _, err := PackSignatures([][]byte{make([]byte, 64), make([]byte, 63)}); err == nil {
+		t.Error("PackSignatures accepted a 63 byte signature")
+	}
+	if _, err := PackSignatures([][]byte{make([]byte, 72)}); err == nil {
+		t.Error("PackSignatures accepted a DER length signature")
+	}
+
+	valid, _ := PackSignatures([][]byte{make([]byte, 64), make([]byte, 64)})
+	for name, data := range map[string][]byte{
+		"empty":         nil,
+		"short count":   {0, 0, 0},
+		"truncated":     valid[:len(valid)-1],
+		"trailing data": append(valid[:len(valid):len(valid)], 0),
+		"count too big": append([]byte{0, 0, 0, 3}, valid[4:]...),
+		"count too low": append([]byte{0, 0, 0, 1}, valid[4:]...),
+		"huge count":    append([]byte{0xff, 0xff, 0xff, 0xff}, valid[4:]...),
+	} {
+		if _, err := UnpackSignatures(data); err == nil {
+			t.Errorf("%s: UnpackSignatures accepted %x", name, data)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package sm2
+
+import (
+	"io"
+	"math/big"
+)
+
+// SignSKF signs hash like Sign and returns the signature in the raw layout
+// used by GM/T 0006 and SKF (GM/T 0016) devices: r followed by s, each a
+// 32 byte big-endian integer, 64 bytes in all. As with Sign, hash is the
+// digest e = SM3(Z_A || M), which is also what SKF_ECCSignData expects.
+//
+// The ECCSIGNATUREBLOB structure of the SKF API itself reserves 64 bytes
+// for each of r and s, with the value in the low 32; callers filling that
+// structure copy each half of this result into the end of its field.
+func SignSKF(rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := Sign(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	return appendRS(nil, r, s), nil
+}
+
+// VerifySKF reports whether sig, in the 64 byte layout produced by
+// SignSKF, is a valid signature of hash by pub.
+func VerifySKF(pub *PublicKey, hash, sig []byte) bool {
+	if len(sig) != rawSignatureSize {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	return Verify(pub, hash, r, s)
+}
@@ -0,0 +1,39 @@
+package sm2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSignSKF(t *testing.T) {
+	priv := vectorKey()
+	e, err := messageDigest(&priv.PublicKey, []byte("message digest"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignSKF(scalarReader(vectorK), priv, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// r, then s, each as 32 big-endian bytes.
+	want, _ := hex.DecodeString("F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3" +
+		"B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA")
+	if !bytes.Equal(sig, want) {
+		t.Errorf("SignSKF = %X, want r || s = %X", sig, want)
+	}
+	if !VerifySKF(&priv.PublicKey, e, sig) {
+		t.Error("VerifySKF rejected the GB/T 32918 signature")
+	}
+
+	swapped := append(append([]byte{}, sig[32:]...), sig[:32]...)
+	for name, bad := range map[string][]byte{
+		"s first":   swapped,
+		"truncated": sig[:63],
+		"DER":       appendSignature(nil, vectorR, vectorS),
+	} {
+		if VerifySKF(&priv.PublicKey, e, bad) {
+			t.Errorf("VerifySKF accepted %s", name)
+		}
+	}
+}
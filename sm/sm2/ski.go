@@ -0,0 +1,20 @@
+package sm2
+
+import "crypto/sm/sm3"
+
+// SubjectKeyID returns the key identifier of pub for the
+// SubjectKeyIdentifier and AuthorityKeyIdentifier certificate extensions:
+// the SM3 hash of the uncompressed point 0x04 || X || Y, which is the
+// content of the subjectPublicKey BIT STRING in the key's
+// SubjectPublicKeyInfo. This is method (1) of RFC 5280, section 4.2.1.2,
+// with SM3 in place of SHA-1. Some CAs still use SHA-1 for SM2 keys, so an
+// identifier read from an existing certificate should be copied rather
+// than recomputed. It returns nil if pub is not a valid point.
+func (pub *PublicKey) SubjectKeyID() []byte {
+	p, err := pub.Marshal()
+	if err != nil {
+		return nil
+	}
+	id := sm3.SumSM3(p)
+	return id[:]
+}
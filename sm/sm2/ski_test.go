@@ -0,0 +1,18 @@
+package sm2
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSubjectKeyID(t *testing.T) {
+	priv := vectorKey()
+	// SM3(04 || X || Y) for the GB/T 32918 example key.
+	const want = "E6A4F9765FCA89E8B7D64019B900D57CD86B4677FA5DC70284C90DE05FA77F9C"
+	if got := fmt.Sprintf("%X", priv.PublicKey.SubjectKeyID()); got != want {
+		t.Errorf("SubjectKeyID = %s, want %s", got, want)
+	}
+	if id := (&PublicKey{Curve: P256Sm2()}).SubjectKeyID(); id != nil {
+		t.Errorf("SubjectKeyID of an empty key = %X, want nil", id)
+	}
+}
@@ -41,6 +41,84 @@ func (priv *PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts)
 	return asn1.Marshal(sm2Signature{r, s})
 }
 
+// SignatureMaxLen returns the maximum length of an ASN.1 DER encoded SM2
+// signature, as produced by Sign and SignTo.
+func SignatureMaxLen() int {
+	// SEQUENCE { INTEGER r, INTEGER s }, each INTEGER at most 32 bytes
+	// plus a leading zero byte.
+	return 2 + 2*(2+1+32)
+}
+
+// SignTo signs hash like PrivateKey.Sign and appends the ASN.1 DER encoded
+// signature to dst, returning the updated slice. If dst has at least
+// SignatureMaxLen bytes of spare capacity no allocation is made for the
+// encoding.
+func SignTo(dst []byte, rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := Sign(rand, priv, hash)
+	if err != nil {
+		return dst, err
+	}
+	return appendSignature(dst, r, s), nil
+}
+
+// SignBoth signs hash like Sign and returns the signature both ASN.1 DER
+// encoded and in raw form, r || s as two 32 byte big-endian integers. Both
+// encodings share a single allocation.
+//
+// hash is not the message: the caller must pass the digest
+// e = SM3(Z_A || M) with Z_A already prefixed, as for Sign.
+// SigningContext.Digest computes it.
+func SignBoth(rand io.Reader, priv *PrivateKey, hash []byte) (der []byte, raw []byte, err error) {
+	r, s, err := Sign(rand, priv, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw = appendRS(make([]byte, 0, rawSignatureSize+SignatureMaxLen()), r, s)
+	return appendSignature(raw[rawSignatureSize:], r, s), raw[:rawSignatureSize:rawSignatureSize], nil
+}
+
+// appendSignature appends the DER encoding of sm2Signature{r, s} to dst.
+// The output is identical to asn1.Marshal for non-negative r and s.
+func appendSignature(dst []byte, r, s *big.Int) []byte {
+	rLen, sLen := derIntLen(r), derIntLen(s)
+	dst = append(dst, 0x30, byte(2+rLen+2+sLen))
+	dst = appendDERInt(dst, r, rLen)
+	dst = appendDERInt(dst, s, sLen)
+	return dst
+}
+
+func derIntLen(x *big.Int) int {
+	n := (x.BitLen() + 7) / 8
+	if n == 0 || x.BitLen()%8 == 0 {
+		n++
+	}
+	return n
+}
+
+func appendDERInt(dst []byte, x *big.Int, n int) []byte {
+	dst = append(dst, 0x02, byte(n))
+	start := len(dst)
+	for i := 0; i < n; i++ {
+		dst = append(dst, 0)
+	}
+	x.FillBytes(dst[start:])
+	return dst
+}
+
+// parseSignature parses an ASN.1 DER encoded signature, rejecting trailing
+// data.
+func parseSignature(sig []byte) (r, s *big.Int, err error) {
+	var sm2Sign sm2Signature
+	rest, err := asn1.Unmarshal(sig, &sm2Sign)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rest) != 0 {
+		return nil, nil, errors.New("sm2: trailing data after signature")
+	}
+	return sm2Sign.R, sm2Sign.S, nil
+}
+
 func (pub *PublicKey) Verify(msg []byte, sign []byte) bool {
 	var sm2Sign sm2Signature
 	_, err := asn1.Unmarshal(sign, &sm2Sign)
@@ -52,12 +130,23 @@ func (pub *PublicKey) Verify(msg []byte, sign []byte) bool {
 
 var one = new(big.Int).SetInt64(1)
 
+// RandScalar returns a uniformly random scalar in [1, N-1], where N is the
+// order of the SM2 curve, reading from rand. It is suitable for private keys,
+// signing nonces and blinding factors. If rand is nil, DefaultRand is used.
+func RandScalar(rand io.Reader) (*big.Int, error) {
+	return randFieldElement(P256Sm2(), rand)
+}
+
+// randFieldElement returns a random element of [1, N-1] for the order N of
+// c, using the method of FIPS 186-4, B.4.1: 64 more bits than needed are
+// read so that the bias of the reduction is negligible. Any error from rand
+// is returned. A nil rand means DefaultRand.
 func randFieldElement(c elliptic.Curve, rand io.Reader) (k *big.Int, err error) {
 	params := c.Params()
 	b := make([]byte, params.BitSize/8+8)
-	_, err = io.ReadFull(rand, b)
+	_, err = io.ReadFull(randReader(rand), b)
 	if err != nil {
-		return
+		return nil, err
 	}
 	k = new(big.Int).SetBytes(b)
 	n := new(big.Int).Sub(params.N, one)
@@ -81,31 +170,49 @@ func GenerateKey(rand io.Reader) (*PrivateKey, error) {
 
 var errZeroParam = errors.New("zero parameter")
 
-//优化，去掉one
-func generateRandK(rand io.Reader, c elliptic.Curve) (k *big.Int) {
-	params := c.Params()
-	b := make([]byte, params.BitSize/8+8)
-	_, err := io.ReadFull(rand, b)
-	if err != nil {
-		return
+var errHashLength = errors.New("sm2: hash must be at least 32 bytes")
+
+// hashToInt converts a digest to the integer e used by Sign and Verify.
+// The digest must be at least 32 bytes: e is its leftmost 32 bytes read as
+// a big-endian integer, and any further bytes are ignored. e is not reduced
+// modulo N; the reduction happens as part of computing r.
+func hashToInt(hash []byte) (*big.Int, error) {
+	if len(hash) < 32 {
+		return nil, errHashLength
 	}
-	k = new(big.Int).SetBytes(b)
-	n := new(big.Int).Sub(params.N, one)
-	k.Mod(k, n)
-	k.Add(k, one)
-	return
+	return new(big.Int).SetBytes(hash[:32]), nil
 }
 
-
+// Sign computes the SM2 signature (r, s) of hash with priv, without any
+// ASN.1 encoding.
+//
+// hash is the value e = SM3(Z_A || M) of GB/T 32918.2, which the caller has
+// already computed; SignWithHash computes it from the message. hash must
+// be at least 32 bytes long. Its leftmost 32 bytes are read as a big-endian
+// integer and any further bytes are ignored, exactly as in Verify, so a
+// signature made with Sign always verifies with Verify over the same hash.
 func Sign(rand io.Reader, priv *PrivateKey, hash []byte) (r, s *big.Int, err error) {
+	e, err := hashToInt(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return SignPrehashedE(rand, priv, e)
+}
+
+// SignPrehashedE performs the scalar step of SM2 signing for a digest that
+// has already been converted to the integer e = SM3(Z_A || M), e.g. by an
+// external hashing device. Sign is SignPrehashedE applied to the leftmost
+// 32 bytes of its hash argument.
+func SignPrehashedE(rand io.Reader, priv *PrivateKey, e *big.Int) (r, s *big.Int, err error) {
 	var one = new(big.Int).SetInt64(1)
-	if len(hash) < 32 {
-		err = errors.New("The length of hash has short than what SM2 need.")
+	if e == nil || e.Sign() < 0 {
+		err = errors.New("sm2: invalid digest value")
 		return
 	}
-	var tmp []byte = hash[0:32]
-	e := new(big.Int).SetBytes(tmp)
-	k := generateRandK(rand, priv.PublicKey.Curve)
+	k, err := randFieldElement(priv.PublicKey.Curve, rand)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	x1, _ := priv.PublicKey.Curve.ScalarBaseMult(k.Bytes())
 
@@ -122,34 +229,84 @@ func Sign(rand io.Reader, priv *PrivateKey, hash []byte) (r, s *big.Int, err err
 
 	s2 := new(big.Int).Add(one, priv.D)
 	s2.Mod(s2, n)
-	s2.ModInverse(s2, n)
+	s2 = fieldForOrder(n).inverse(s2)
 	s = new(big.Int).Mul(s1, s2)
 	s.Mod(s, n)
 
 	return
 }
 
+// Verify reports whether (r, s) is a valid SM2 signature of hash by pub.
+// hash has the same meaning as in Sign and is converted identically: a
+// hash shorter than 32 bytes is rejected, and bytes beyond the 32nd are
+// ignored.
+//
+// Malformed input, including nil keys or scalars and keys on a curve other
+// than P256Sm2, is rejected with cheap checks before any point arithmetic,
+// so invalid signatures cost far less than valid ones. VerifyE performs the
+// same checks and reports which one failed.
 func Verify(pub *PublicKey, hash []byte, r, s *big.Int) bool {
+	return VerifyE(pub, hash, r, s) == nil
+}
+
+var (
+	errNilPublicKey   = errors.New("sm2: missing public key")
+	errSignatureRange = errors.New("sm2: signature value missing or out of range")
+	errVerifyMismatch = errors.New("sm2: signature does not match")
+	errZeroSum        = errors.New("sm2: r + s is zero modulo N")
+)
+
+// VerifyE is Verify returning an error in place of false: nil if (r, s) is
+// a valid signature of hash by pub, and otherwise an error saying whether
+// the hash, the key or the signature was malformed or the signature simply
+// did not match.
+func VerifyE(pub *PublicKey, hash []byte, r, s *big.Int) error {
+	if len(hash) < 32 {
+		return errHashLength
+	}
+	if r == nil || s == nil {
+		return errSignatureRange
+	}
+	if pub == nil || pub.Curve == nil || pub.X == nil || pub.Y == nil {
+		return errNilPublicKey
+	}
+	if !isSM2Curve(pub.Curve) {
+		return errCurveMismatch
+	}
 	c := pub.Curve
-	N := c.Params().N
+	n := c.Params().N
 
-	if r.Sign() <= 0 || s.Sign() <= 0 {
-		return false
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return errSignatureRange
 	}
-	if r.Cmp(N) >= 0 || s.Cmp(N) >= 0 {
-		return false
+	if err := pub.validate(); err != nil {
+		return err
+	}
+	e, err := hashToInt(hash)
+	if err != nil {
+		return err
 	}
 
-	n := pub.Curve.Params().N
-	e := new(big.Int).SetBytes(hash)
+	// t = (r + s) mod n must not be zero, GB/T 32918.2 step B5.
 	t := new(big.Int).Add(r, s)
-	x11, y11 := pub.Curve.ScalarMult(pub.X, pub.Y, t.Bytes())
-	x12, y12 := pub.Curve.ScalarBaseMult(s.Bytes())
-	x1, _ := pub.Curve.Add(x11, y11, x12, y12)
-	x := new(big.Int).Add(e, x1)
-	x = x.Mod(x, n)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return errZeroSum
+	}
+	x11, y11 := c.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x12, y12 := c.ScalarBaseMult(s.Bytes())
+	x1, y1 := c.Add(x11, y11, x12, y12)
+	if isIdentity(x1, y1) {
+		return errVerifyMismatch
+	}
 
-	return x.Cmp(r) == 0
+	// R = (e + x1) mod n, computed in place in x1.
+	x1.Add(x1, e)
+	x1.Mod(x1, n)
+	if x1.Cmp(r) != 0 {
+		return errVerifyMismatch
+	}
+	return nil
 }
 
 type zr struct {
@@ -6,9 +6,15 @@
 package sm2
 
 import (
+	"bytes"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"testing"
 	"crypto/sm/sm3"
 )
@@ -86,6 +92,215 @@ func BenchmarkSign(b *testing.B) {
 	}
 }
 
+func BenchmarkVerify(b *testing.B) {
+	hashed := sm3.SumSM3([]byte("testing"))
+	priv, _ := GenerateKey(rand.Reader)
+	r, s, _ := Sign(rand.Reader, priv, hashed[:])
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(&priv.PublicKey, hashed[:], r, s)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	n := pub.Curve.Params().N
+	hashed := sm3.SumSM3([]byte("testing"))
+	r, s, err := Sign(rand.Reader, priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(pub, hashed[:], r, s) {
+		t.Fatal("valid signature rejected")
+	}
+
+	other := sm3.SumSM3([]byte("testing!"))
+	cases := []struct {
+		name string
+		hash []byte
+		r, s *big.Int
+	}{
+		{"wrong message", other[:], r, s},
+		{"r = 0", hashed[:], new(big.Int), s},
+		{"s = 0", hashed[:], r, new(big.Int)},
+		{"r = n", hashed[:], n, s},
+		{"s = n", hashed[:], r, n},
+		{"r + s = n", hashed[:], r, new(big.Int).Sub(n, r)},
+		{"r + 1", hashed[:], new(big.Int).Add(r, one), s},
+	}
+	for _, c := range cases {
+		if Verify(pub, c.hash, c.r, c.s) {
+			t.Errorf("%s: Verify succeeded", c.name)
+		}
+	}
+}
+
+// TestVerifyZeroT checks every verification path rejects signatures with
+// r + s = 0 mod n, where t·P is the identity and the signature would no
+// longer depend on the key.
+func TestVerifyZeroT(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	n := pub.Curve.Params().N
+	hashed := sm3.SumSM3([]byte("testing"))
+	v, err := NewVerifier(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	random, _ := RandScalar(rand.Reader)
+	for _, s := range []*big.Int{one, new(big.Int).Sub(n, one), random} {
+		r := new(big.Int).Sub(n, s)
+		if Verify(pub, hashed[:], r, s) {
+			t.Errorf("Verify accepted r = n - %x", s)
+		}
+		if v.Verify(hashed[:], r, s) {
+			t.Errorf("Verifier accepted r = n - %x", s)
+		}
+		if res := VerifyDebug(pub, hashed[:], r, s); res.Match || res.Err == nil {
+			t.Errorf("VerifyDebug(r = n - %x) = %+v, want an error", s, res)
+		}
+		sig, _ := asn1.Marshal(sm2Signature{r, s})
+		if pub.Verify(hashed[:], sig) {
+			t.Errorf("PublicKey.Verify accepted r = n - %x", s)
+		}
+	}
+}
+
+func TestVerifyE(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	hashed := sm3.SumSM3([]byte("testing"))
+	r, s, err := Sign(rand.Reader, priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyE(pub, hashed[:], r, s); err != nil {
+		t.Fatalf("valid signature: %v", err)
+	}
+
+	// A CurveParams with the SM2 values, but not P256Sm2 itself, is the
+	// same curve.
+	params := *P256Sm2().Params()
+	if err := VerifyE(&PublicKey{Curve: &params, X: pub.X, Y: pub.Y}, hashed[:], r, s); err != nil {
+		t.Errorf("key on a copy of the SM2 parameters: %v", err)
+	}
+
+	p256 := elliptic.P256()
+	px, py := p256.ScalarBaseMult(priv.D.Bytes())
+	n := P256Sm2().Params().N
+	other := sm3.SumSM3([]byte("testing!"))
+	cases := []struct {
+		name string
+		pub  *PublicKey
+		hash []byte
+		r, s *big.Int
+		err  error
+	}{
+		{"zero value key", &PublicKey{}, hashed[:], r, s, errNilPublicKey},
+		{"nil curve", &PublicKey{X: pub.X, Y: pub.Y}, hashed[:], r, s, errNilPublicKey},
+		{"nil key", nil, hashed[:], r, s, errNilPublicKey},
+		{"P-256 key", &PublicKey{Curve: p256, X: px, Y: py}, hashed[:], r, s, errCurveMismatch},
+		{"short hash", pub, hashed[:31], r, s, errHashLength},
+		{"nil r", pub, hashed[:], nil, s, errSignatureRange},
+		{"s = n", pub, hashed[:], r, n, errSignatureRange},
+		{"r + s = n", pub, hashed[:], r, new(big.Int).Sub(n, r), errZeroSum},
+		{"wrong message", pub, other[:], r, s, errVerifyMismatch},
+	}
+	for _, c := range cases {
+		if err := VerifyE(c.pub, c.hash, c.r, c.s); err != c.err {
+			t.Errorf("%s: VerifyE = %v, want %v", c.name, err, c.err)
+		}
+		if Verify(c.pub, c.hash, c.r, c.s) {
+			t.Errorf("%s: Verify succeeded", c.name)
+		}
+		if res := VerifyDebug(c.pub, c.hash, c.r, c.s); res.Match {
+			t.Errorf("%s: VerifyDebug matched", c.name)
+		}
+	}
+	if _, err := NewVerifier(&PublicKey{Curve: p256, X: px, Y: py}); err != errCurveMismatch {
+		t.Errorf("NewVerifier with a P-256 key: err = %v, want %v", err, errCurveMismatch)
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	n := pub.Curve.Params().N
+	hashed := sm3.SumSM3([]byte("testing"))
+	r, s, _ := Sign(rand.Reader, priv, hashed[:])
+
+	cases := []struct {
+		name string
+		pub  *PublicKey
+		hash []byte
+		r, s *big.Int
+	}{
+		{"nil key", nil, hashed[:], r, s},
+		{"nil curve", &PublicKey{X: pub.X, Y: pub.Y}, hashed[:], r, s},
+		{"nil X", &PublicKey{Curve: pub.Curve, Y: pub.Y}, hashed[:], r, s},
+		{"nil Y", &PublicKey{Curve: pub.Curve, X: pub.X}, hashed[:], r, s},
+		{"nil r", pub, hashed[:], nil, s},
+		{"nil s", pub, hashed[:], r, nil},
+		{"nil hash", pub, nil, r, s},
+		{"short hash", pub, hashed[:16], r, s},
+		{"negative r", pub, hashed[:], new(big.Int).Neg(r), s},
+		{"s > n", pub, hashed[:], r, new(big.Int).Add(n, one)},
+	}
+	for _, c := range cases {
+		// Rejection must happen before any curve arithmetic, which is
+		// what allocates.
+		allocs := testing.AllocsPerRun(10, func() {
+			if Verify(c.pub, c.hash, c.r, c.s) {
+				t.Errorf("%s: Verify succeeded", c.name)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%s: Verify made %v allocations before rejecting", c.name, allocs)
+		}
+	}
+
+	var nilPub *PublicKey
+	if nilPub.Verify(hashed[:], []byte{0x30, 0x00}) {
+		t.Error("PublicKey.Verify succeeded on a nil key")
+	}
+	if pub.Verify(hashed[:], []byte{0xff, 0x01}) {
+		t.Error("PublicKey.Verify succeeded on garbage")
+	}
+}
+
+func TestHashLength(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	long := make([]byte, 33)
+	for i := range long {
+		long[i] = byte(0xa0 + i)
+	}
+
+	if _, _, err := Sign(rand.Reader, priv, long[:31]); err == nil {
+		t.Error("Sign accepted a 31 byte hash")
+	}
+	r, s, err := Sign(rand.Reader, priv, long[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Verify(pub, long[:31], r, s) {
+		t.Error("Verify accepted a 31 byte hash")
+	}
+	// Only the leftmost 32 bytes count, on both sides.
+	if !Verify(pub, long[:32], r, s) || !Verify(pub, long, r, s) {
+		t.Error("signature over the 32 byte prefix did not verify")
+	}
+	r, s, err = Sign(rand.Reader, priv, long)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(pub, long, r, s) || !Verify(pub, long[:32], r, s) {
+		t.Error("signature over a 33 byte hash did not verify against its prefix")
+	}
+}
+
 func TestSignAndVerify(t *testing.T) {
 	priv, _ := GenerateKey(rand.Reader)
 
@@ -112,3 +327,143 @@ func TestSignAndVerify(t *testing.T) {
 		t.Errorf("Verify always works!")
 	}
 }
+
+func TestSignTo(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	hashed := sm3.SumSM3([]byte("testing"))
+	seed := make([]byte, 40)
+	for i := range seed {
+		seed[i] = byte(i * 7)
+	}
+
+	want, err := priv.Sign(bytes.NewReader(seed), hashed[:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 0, SignatureMaxLen())
+	got, err := SignTo(buf, bytes.NewReader(seed), priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("SignTo = %x, want %x", got, want)
+	}
+	if len(got) > SignatureMaxLen() {
+		t.Errorf("signature length %d exceeds SignatureMaxLen %d", len(got), SignatureMaxLen())
+	}
+	if !priv.PublicKey.Verify(hashed[:], got) {
+		t.Error("SignTo produced a signature that does not verify")
+	}
+}
+
+func TestSignBoth(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	hashed := sm3.SumSM3([]byte("testing"))
+	der, raw, err := SignBoth(rand.Reader, priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != 64 {
+		t.Fatalf("raw signature is %d bytes, want 64", len(raw))
+	}
+	var sig sm2Signature
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		t.Fatal(err)
+	}
+	r, s := new(big.Int).SetBytes(raw[:32]), new(big.Int).SetBytes(raw[32:])
+	if sig.R.Cmp(r) != 0 || sig.S.Cmp(s) != 0 {
+		t.Errorf("DER (%x, %x) and raw (%x, %x) differ", sig.R, sig.S, r, s)
+	}
+	if !Verify(&priv.PublicKey, hashed[:], r, s) {
+		t.Error("SignBoth produced a signature that does not verify")
+	}
+	// Appending to raw must not clobber der.
+	before := append([]byte{}, der...)
+	_ = append(raw, 0xff)
+	if !bytes.Equal(der, before) {
+		t.Error("raw and DER outputs overlap")
+	}
+}
+
+func TestAppendSignatureMatchesASN1(t *testing.T) {
+	n := P256Sm2().Params().N
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(0x7f),
+		big.NewInt(0x80),
+		new(big.Int).Sub(n, one),
+		new(big.Int).Rsh(n, 8),
+	}
+	for _, r := range values {
+		for _, s := range values {
+			want, err := asn1.Marshal(sm2Signature{r, s})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := appendSignature(nil, r, s); !bytes.Equal(got, want) {
+				t.Errorf("appendSignature(%x, %x) = %x, want %x", r, s, got, want)
+			}
+		}
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("entropy source failed") }
+
+func TestRandScalar(t *testing.T) {
+	n := P256Sm2().Params().N
+	for i := 0; i < 100; i++ {
+		k, err := RandScalar(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Sign() <= 0 || k.Cmp(n) >= 0 {
+			t.Fatalf("RandScalar = %x, out of [1, N-1]", k)
+		}
+	}
+	// An all-zero and an all-ones source hit both ends of the reduction.
+	if k, _ := RandScalar(zeroReader); k.Cmp(one) != 0 {
+		t.Errorf("RandScalar(zero source) = %x, want 1", k)
+	}
+	ones := bytes.NewReader(bytes.Repeat([]byte{0xff}, 40))
+	if k, _ := RandScalar(ones); k.Sign() <= 0 || k.Cmp(n) >= 0 {
+		t.Errorf("RandScalar(all-ones source) = %x, out of range", k)
+	}
+
+	if k, err := RandScalar(errReader{}); err == nil || k != nil {
+		t.Errorf("RandScalar with a failing reader = %v, %v; want nil, error", k, err)
+	}
+	if _, err := RandScalar(bytes.NewReader(make([]byte, 39))); err == nil {
+		t.Error("RandScalar accepted a short read")
+	}
+}
+
+func TestSignFailingReader(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	hashed := sm3.SumSM3([]byte("testing"))
+	for _, r := range []io.Reader{errReader{}, bytes.NewReader(make([]byte, 10))} {
+		if r, s, err := Sign(r, priv, hashed[:]); err == nil || r != nil || s != nil {
+			t.Errorf("Sign with a failing reader = (%v, %v, %v), want an error and no signature", r, s, err)
+		}
+	}
+	if sig, err := priv.Sign(errReader{}, hashed[:], nil); err == nil || sig != nil {
+		t.Errorf("PrivateKey.Sign with a failing reader = %x, %v", sig, err)
+	}
+	if sig, err := SignTo(nil, errReader{}, priv, hashed[:]); err == nil || len(sig) != 0 {
+		t.Errorf("SignTo with a failing reader = %x, %v", sig, err)
+	}
+	if _, _, err := SignWithHash(errReader{}, priv, []byte("testing"), nil, 0); err == nil {
+		t.Error("SignWithHash succeeded with a failing reader")
+	}
+	if _, err := GenerateKey(errReader{}); err == nil {
+		t.Error("GenerateKey succeeded with a failing reader")
+	}
+	if _, err := Encrypt(errReader{}, &priv.PublicKey, []byte("msg")); err == nil {
+		t.Error("Encrypt succeeded with a failing reader")
+	}
+	if _, _, err := Encapsulate(errReader{}, &priv.PublicKey); err == nil {
+		t.Error("Encapsulate succeeded with a failing reader")
+	}
+}
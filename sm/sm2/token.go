@@ -0,0 +1,78 @@
+package sm2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Tokens use the JWS compact serialization, header.payload.signature, each
+// part base64url encoded without padding. The header is {"alg":"SM2",
+// "typ":"JWT"} and the signature is r || s, each a 32 byte big-endian value,
+// computed over "header.payload" with the default uid and Z_A.
+const tokenAlg = "SM2"
+
+var errInvalidToken = errors.New("sm2: invalid token")
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// SignToken returns a compact signed token carrying claims.
+func SignToken(priv *PrivateKey, claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(tokenHeader{Alg: tokenAlg, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	r, s, err := SignWithHash(nil, priv, []byte(signingInput), nil, 0)
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + enc.EncodeToString(appendRS(nil, r, s)), nil
+}
+
+// VerifyToken checks the signature of a token produced by SignToken and
+// returns its claims.
+func VerifyToken(pub *PublicKey, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidToken
+	}
+	enc := base64.RawURLEncoding
+	headerJSON, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	var header tokenHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != tokenAlg {
+		return nil, errInvalidToken
+	}
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		return nil, errInvalidToken
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	signingInput := parts[0] + "." + parts[1]
+	if !VerifyWithHash(pub, []byte(signingInput), nil, 0, r, s) {
+		return nil, errors.New("sm2: token signature verification failed")
+	}
+	payload, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errInvalidToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,46 @@
+package sm2
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestToken(t *testing.T) {
+	priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := map[string]interface{}{"sub": "alice", "admin": false}
+	token, err := SignToken(priv, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := VerifyToken(&priv.PublicKey, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["sub"] != "alice" || got["admin"] != false {
+		t.Errorf("VerifyToken claims = %v, want %v", got, claims)
+	}
+
+	parts := strings.Split(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"admin":true,"sub":"alice"}`))
+	if _, err := VerifyToken(&priv.PublicKey, parts[0]+"."+forged+"."+parts[2]); err == nil {
+		t.Error("VerifyToken accepted a tampered payload")
+	}
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	if _, err := VerifyToken(&priv.PublicKey, none+"."+parts[1]+"."+parts[2]); err == nil {
+		t.Error("VerifyToken accepted a token with a foreign alg")
+	}
+	other, _ := GenerateKey(rand.Reader)
+	if _, err := VerifyToken(&other.PublicKey, token); err == nil {
+		t.Error("VerifyToken accepted a token under the wrong key")
+	}
+	for _, bad := range []string{"", "a.b", parts[0] + "." + parts[1] + ".!!", token + ".x"} {
+		if _, err := VerifyToken(&priv.PublicKey, bad); err == nil {
+			t.Errorf("VerifyToken(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sm2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// The vectors below are the worked examples over the recommended curve from
+// GB/T 32918.5 (GM/T 0003.5), using the private key vectorD, the random
+// scalar vectorK and the default uid.
+var (
+	vectorX  = fromHex("09F9DF311E5421A150DD7D161E4BC5C672179FAD1833FC076BB08FF356F35020")
+	vectorY  = fromHex("CCEA490CE26775A52DC6EA718CC1AA600AED05FBF35E084A6632F6072DA9AD13")
+	vectorZA = "B2E14C5C79C6DF5B85F4FE7ED8DB7A262B9DA7E07CCB0EA9F4747B8CCDA8A4F3"
+	vectorE  = "F0B43E94BA45ACCAACE692ED534382EB17E6AB5A19CE7B31F4486FDFC0D28640"
+	vectorR  = fromHex("F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3")
+	vectorS  = fromHex("B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA")
+
+	vectorCiphertext = "04" +
+		"04EBFC718E8D1798620432268E77FEB6415E2EDE0E073C0F4F640ECD2E149A73" +
+		"E858F9D81E5430A57B36DAAB8F950A3C64E6EE6A63094D99283AFF767E124DF0" +
+		"59983C18F809E262923C53AEC295D30383B54E39D609D160AFCB1908D0BD8766" +
+		"21886CA989CA9C7D58087307CA93092D651EFA"
+)
+
+func TestVectorPublicKey(t *testing.T) {
+	priv := vectorKey()
+	if priv.X.Cmp(vectorX) != 0 || priv.Y.Cmp(vectorY) != 0 {
+		t.Errorf("public key = (%X, %X), want (%X, %X)", priv.X, priv.Y, vectorX, vectorY)
+	}
+}
+
+func TestVectorZA(t *testing.T) {
+	priv := vectorKey()
+	za, err := ZA(&priv.PublicKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%X", za); got != vectorZA {
+		t.Errorf("ZA = %s, want %s", got, vectorZA)
+	}
+	e, err := messageDigest(&priv.PublicKey, []byte("message digest"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%X", e); got != vectorE {
+		t.Errorf("e = %s, want %s", got, vectorE)
+	}
+}
+
+func TestVectorSign(t *testing.T) {
+	priv := vectorKey()
+	msg := []byte("message digest")
+	r, s, err := SignWithHash(scalarReader(vectorK), priv, msg, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Cmp(vectorR) != 0 || s.Cmp(vectorS) != 0 {
+		t.Errorf("signature = (%X, %X), want (%X, %X)", r, s, vectorR, vectorS)
+	}
+	if !VerifyWithHash(&priv.PublicKey, msg, nil, 0, vectorR, vectorS) {
+		t.Error("the published signature does not verify")
+	}
+}
+
+func TestVectorEncrypt(t *testing.T) {
+	priv := vectorKey()
+	msg := []byte("encryption standard")
+	want, _ := hex.DecodeString(vectorCiphertext)
+	ct, err := Encrypt(scalarReader(vectorK), &priv.PublicKey, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ct, want) {
+		t.Errorf("Encrypt = %X, want %X", ct, want)
+	}
+	got, err := Decrypt(priv, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("Decrypt = %q, want %q", got, msg)
+	}
+}
+
+func TestVectorEncryptWithEphemeral(t *testing.T) {
+	priv := vectorKey()
+	msg := []byte("encryption standard")
+	want, _ := hex.DecodeString(vectorCiphertext)
+	ephemeral := &PrivateKey{D: vectorK}
+	for i := 0; i < 2; i++ {
+		ct, err := EncryptWithEphemeral(&priv.PublicKey, ephemeral, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ct, want) {
+			t.Errorf("EncryptWithEphemeral = %X, want %X", ct, want)
+		}
+	}
+
+	n := priv.Curve.Params().N
+	for _, d := range []*big.Int{nil, new(big.Int), n} {
+		if _, err := EncryptWithEphemeral(&priv.PublicKey, &PrivateKey{D: d}, msg); err == nil {
+			t.Errorf("EncryptWithEphemeral accepted the ephemeral scalar %v", d)
+		}
+	}
+}
+
+// TestSignVerifyContract locks the hash handling of the low-level Sign and
+// Verify: hash is e = SM3(Z_A || M), read as the big-endian integer of its
+// leftmost 32 bytes.
+func TestSignVerifyContract(t *testing.T) {
+	priv := vectorKey()
+	e, _ := hex.DecodeString(vectorE)
+	r, s, err := Sign(scalarReader(vectorK), priv, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Cmp(vectorR) != 0 || s.Cmp(vectorS) != 0 {
+		t.Errorf("Sign(e) = (%X, %X), want (%X, %X)", r, s, vectorR, vectorS)
+	}
+	if !Verify(&priv.PublicKey, e, vectorR, vectorS) {
+		t.Error("Verify(e) rejected the published signature")
+	}
+
+	extended := append(append([]byte{}, e...), 0xde, 0xad)
+	r2, s2, err := Sign(scalarReader(vectorK), priv, extended)
+	if err != nil || r2.Cmp(r) != 0 || s2.Cmp(s) != 0 {
+		t.Error("Sign did not ignore bytes beyond the 32nd")
+	}
+	if !Verify(&priv.PublicKey, extended, vectorR, vectorS) {
+		t.Error("Verify did not ignore bytes beyond the 32nd")
+	}
+
+	if _, _, err := Sign(scalarReader(vectorK), priv, e[:31]); err != errHashLength {
+		t.Errorf("Sign with a 31 byte hash: err = %v, want %v", err, errHashLength)
+	}
+	if Verify(&priv.PublicKey, e[:31], vectorR, vectorS) {
+		t.Error("Verify accepted a 31 byte hash")
+	}
+	// A leading zero byte is significant; e is not re-aligned.
+	shifted := append([]byte{0}, e...)
+	if Verify(&priv.PublicKey, shifted, vectorR, vectorS) {
+		t.Error("Verify accepted e shifted right by one byte")
+	}
+}
@@ -0,0 +1,262 @@
+package sm2
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// Verifier verifies SM2 signatures by one public key. It does the point
+// arithmetic in Jacobian coordinates on fixed-size Montgomery limbs rather
+// than through elliptic.Curve, so a verification makes no heap allocations
+// once the Verifier exists. A Verifier is safe for concurrent use.
+//
+// Verify has the same semantics as the package-level Verify; the two are
+// interchangeable.
+type Verifier struct {
+	pub *PublicKey
+	// table holds G, P and G + P for the joint scalar multiplication.
+	table [3]jacobianPoint
+}
+
+// jacobianPoint is a point (X/Z², Y/Z³) with coordinates in Montgomery
+// form modulo P. Z = 0 is the point at infinity.
+type jacobianPoint struct {
+	x, y, z [4]uint64
+}
+
+var (
+	verifyFieldOnce sync.Once
+	verifyField     *montField // arithmetic modulo the SM2 prime
+	verifyOrder     [4]uint64  // the SM2 order as limbs
+)
+
+func initVerifyField() {
+	params := P256Sm2().Params()
+	verifyField = newMontField(params.P)
+	verifyOrder = toLimbs(params.N)
+}
+
+// NewVerifier returns a Verifier for pub, which must be a point on the SM2
+// curve other than the identity.
+func NewVerifier(pub *PublicKey) (*Verifier, error) {
+	if err := pub.validate(); err != nil {
+		return nil, err
+	}
+	if !isSM2Curve(pub.Curve) {
+		return nil, errCurveMismatch
+	}
+	verifyFieldOnce.Do(initVerifyField)
+	f := verifyField
+	params := pub.Curve.Params()
+	v := &Verifier{pub: pub}
+	v.table[0] = f.affineToJacobian(params.Gx, params.Gy)
+	v.table[1] = f.affineToJacobian(pub.X, pub.Y)
+	f.pointAdd(&v.table[2], &v.table[0], &v.table[1])
+	return v, nil
+}
+
+// Verify reports whether (r, s) is a valid signature of hash by the
+// Verifier's public key. hash is handled as in Sign.
+func (v *Verifier) Verify(hash []byte, r, s *big.Int) bool {
+	if len(hash) < 32 || r == nil || s == nil {
+		return false
+	}
+	n := v.pub.Curve.Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return false
+	}
+	f := verifyField
+	rl, sl := toLimbs(r), toLimbs(s)
+
+	// t = (r + s) mod n must not be zero.
+	var t [4]uint64
+	modAdd(&t, &rl, &sl, &verifyOrder)
+	if isZeroLimbs(&t) {
+		return false
+	}
+
+	// (x1, y1) = s·G + t·P by Shamir's trick.
+	var q jacobianPoint
+	for i := 255; i >= 0; i-- {
+		f.pointDouble(&q, &q)
+		idx := sl[i/64]>>uint(i%64)&1 | (t[i/64]>>uint(i%64)&1)<<1
+		if idx != 0 {
+			f.pointAdd(&q, &q, &v.table[idx-1])
+		}
+	}
+	if isZeroLimbs(&q.z) {
+		return false
+	}
+	var zinv, x1 [4]uint64
+	f.invertMont(&zinv, &q.z)
+	f.mul(&zinv, &zinv, &zinv)
+	f.mul(&x1, &q.x, &zinv)
+	f.mul(&x1, &x1, &[4]uint64{1})
+
+	// R = (e + x1) mod n. Both e < 2²⁵⁶ and x1 < P are below 2n.
+	e := bytesToLimbs(hash[:32])
+	reduceOnce(&e, &verifyOrder)
+	reduceOnce(&x1, &verifyOrder)
+	modAdd(&x1, &x1, &e, &verifyOrder)
+	return x1 == rl
+}
+
+func (f *montField) affineToJacobian(x, y *big.Int) jacobianPoint {
+	var p jacobianPoint
+	xl, yl := toLimbs(x), toLimbs(y)
+	f.mul(&p.x, &xl, &f.rr)
+	f.mul(&p.y, &yl, &f.rr)
+	p.z = f.one
+	return p
+}
+
+// pointDouble sets q = 2p for a curve with a = -3, using the formulas
+// dbl-2001-b of the Explicit-Formulas Database. q may alias p.
+func (f *montField) pointDouble(q, p *jacobianPoint) {
+	if isZeroLimbs(&p.z) {
+		*q = *p
+		return
+	}
+	m := &f.n
+	var delta, gamma, beta, alpha, t1, t2 [4]uint64
+	f.mul(&delta, &p.z, &p.z)
+	f.mul(&gamma, &p.y, &p.y)
+	f.mul(&beta, &p.x, &gamma)
+	modSub(&t1, &p.x, &delta, m)
+	modAdd(&t2, &p.x, &delta, m)
+	f.mul(&alpha, &t1, &t2)
+	modAdd(&t1, &alpha, &alpha, m)
+	modAdd(&alpha, &t1, &alpha, m) // alpha = 3(X - δ)(X + δ)
+
+	var x3, y3, z3 [4]uint64
+	f.mul(&x3, &alpha, &alpha)
+	modAdd(&t1, &beta, &beta, m)
+	modAdd(&t1, &t1, &t1, m) // 4β
+	modAdd(&t2, &t1, &t1, m) // 8β
+	modSub(&x3, &x3, &t2, m)
+
+	modAdd(&z3, &p.y, &p.z, m)
+	f.mul(&z3, &z3, &z3)
+	modSub(&z3, &z3, &gamma, m)
+	modSub(&z3, &z3, &delta, m)
+
+	modSub(&t1, &t1, &x3, m)
+	f.mul(&y3, &alpha, &t1)
+	f.mul(&t2, &gamma, &gamma)
+	modAdd(&t2, &t2, &t2, m)
+	modAdd(&t2, &t2, &t2, m)
+	modAdd(&t2, &t2, &t2, m) // 8γ²
+	modSub(&y3, &y3, &t2, m)
+
+	q.x, q.y, q.z = x3, y3, z3
+}
+
+// pointAdd sets q = a + b. q may alias a or b.
+func (f *montField) pointAdd(q, a, b *jacobianPoint) {
+	if isZeroLimbs(&a.z) {
+		*q = *b
+		return
+	}
+	if isZeroLimbs(&b.z) {
+		*q = *a
+		return
+	}
+	m := &f.n
+	var z1z1, z2z2, u1, u2, s1, s2, h, r [4]uint64
+	f.mul(&z1z1, &a.z, &a.z)
+	f.mul(&z2z2, &b.z, &b.z)
+	f.mul(&u1, &a.x, &z2z2)
+	f.mul(&u2, &b.x, &z1z1)
+	f.mul(&s1, &a.y, &b.z)
+	f.mul(&s1, &s1, &z2z2)
+	f.mul(&s2, &b.y, &a.z)
+	f.mul(&s2, &s2, &z1z1)
+	modSub(&h, &u2, &u1, m)
+	modSub(&r, &s2, &s1, m)
+	if isZeroLimbs(&h) {
+		if isZeroLimbs(&r) {
+			f.pointDouble(q, a)
+		} else {
+			*q = jacobianPoint{}
+		}
+		return
+	}
+
+	var hh, hhh, v, x3, y3, z3, t [4]uint64
+	f.mul(&hh, &h, &h)
+	f.mul(&hhh, &h, &hh)
+	f.mul(&v, &u1, &hh)
+	f.mul(&x3, &r, &r)
+	modSub(&x3, &x3, &hhh, m)
+	modSub(&x3, &x3, &v, m)
+	modSub(&x3, &x3, &v, m)
+	modSub(&t, &v, &x3, m)
+	f.mul(&y3, &r, &t)
+	f.mul(&t, &s1, &hhh)
+	modSub(&y3, &y3, &t, m)
+	f.mul(&z3, &a.z, &b.z)
+	f.mul(&z3, &z3, &h)
+
+	q.x, q.y, q.z = x3, y3, z3
+}
+
+// modAdd sets z = x + y mod m for x, y < m. z may alias x or y.
+func modAdd(z, x, y, m *[4]uint64) {
+	var sum, d [4]uint64
+	var carry, borrow uint64
+	for i := 0; i < 4; i++ {
+		sum[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+	for i := 0; i < 4; i++ {
+		d[i], borrow = bits.Sub64(sum[i], m[i], borrow)
+	}
+	if carry == 1 || borrow == 0 {
+		*z = d
+	} else {
+		*z = sum
+	}
+}
+
+// modSub sets z = x - y mod m for x, y < m. z may alias x or y.
+func modSub(z, x, y, m *[4]uint64) {
+	var d [4]uint64
+	var borrow uint64
+	for i := 0; i < 4; i++ {
+		d[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	if borrow == 1 {
+		var carry uint64
+		for i := 0; i < 4; i++ {
+			d[i], carry = bits.Add64(d[i], m[i], carry)
+		}
+	}
+	*z = d
+}
+
+// reduceOnce subtracts m from x if x >= m.
+func reduceOnce(x, m *[4]uint64) {
+	var d [4]uint64
+	var borrow uint64
+	for i := 0; i < 4; i++ {
+		d[i], borrow = bits.Sub64(x[i], m[i], borrow)
+	}
+	if borrow == 0 {
+		*x = d
+	}
+}
+
+func isZeroLimbs(x *[4]uint64) bool {
+	return x[0]|x[1]|x[2]|x[3] == 0
+}
+
+// bytesToLimbs converts 32 big-endian bytes to limbs without allocating.
+func bytesToLimbs(b []byte) [4]uint64 {
+	var l [4]uint64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			l[i] |= uint64(b[31-8*i-j]) << uint(8*j)
+		}
+	}
+	return l
+}
@@ -0,0 +1,78 @@
+package sm2
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func TestVerifier(t *testing.T) {
+	priv := vectorKey()
+	v, err := NewVerifier(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, _ := hex.DecodeString(vectorE)
+	if !v.Verify(e, vectorR, vectorS) {
+		t.Error("Verifier rejected the published signature")
+	}
+
+	n := priv.Curve.Params().N
+	for i := 0; i < 20; i++ {
+		k, _ := GenerateKey(nil)
+		v, err := NewVerifier(&k.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := sm3.SumSM3([]byte{byte(i)})
+		r, s, err := Sign(nil, k, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := sm3.SumSM3([]byte{byte(i), 1})
+		for _, c := range []struct {
+			hash []byte
+			r, s *big.Int
+		}{
+			{hashed[:], r, s},
+			{other[:], r, s},
+			{hashed[:], s, r},
+			{hashed[:], new(big.Int).Sub(n, r), s},
+			{hashed[:], r, new(big.Int).Sub(n, r)},
+			{hashed[:31], r, s},
+		} {
+			if got, want := v.Verify(c.hash, c.r, c.s), Verify(&k.PublicKey, c.hash, c.r, c.s); got != want {
+				t.Fatalf("Verifier.Verify = %v, Verify = %v", got, want)
+			}
+		}
+	}
+
+	// s·G + t·P is the identity for P = G, s = 1, r = N - 2.
+	c := priv.Curve.Params()
+	g, err := NewVerifier(&PublicKey{Curve: priv.Curve, X: c.Gx, Y: c.Gy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sm3.SumSM3([]byte("identity"))
+	if g.Verify(h[:], new(big.Int).Sub(n, big.NewInt(2)), big.NewInt(1)) {
+		t.Error("Verifier accepted an identity intermediate")
+	}
+
+	if _, err := NewVerifier(&PublicKey{Curve: priv.Curve, X: new(big.Int), Y: new(big.Int)}); err == nil {
+		t.Error("NewVerifier accepted the identity")
+	}
+}
+
+func BenchmarkVerifier(b *testing.B) {
+	hashed := sm3.SumSM3([]byte("testing"))
+	priv, _ := GenerateKey(nil)
+	r, s, _ := Sign(nil, priv, hashed[:])
+	v, _ := NewVerifier(&priv.PublicKey)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Verify(hashed[:], r, s)
+	}
+}
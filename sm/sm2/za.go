@@ -0,0 +1,163 @@
+package sm2
+
+import (
+	"crypto"
+	"errors"
+	"io"
+	"math/big"
+
+	"crypto/sm/sm3"
+)
+
+// defaultUID is the signer identity used by GB/T 35276 when none is given.
+var defaultUID = []byte("1234567812345678")
+
+var errUIDTooLong = errors.New("sm2: uid too long")
+
+// ZA returns Z_A = SM3(ENTL || ID || a || b || xG || yG || xA || yA) as
+// defined in GB/T 32918.2, the hash of the signer's identity uid and the
+// domain parameters. An empty uid selects the default "1234567812345678".
+func ZA(pub *PublicKey, uid []byte) ([]byte, error) {
+	if len(uid) == 0 {
+		uid = defaultUID
+	}
+	if len(uid) >= 8192 {
+		return nil, errUIDTooLong
+	}
+	params := pub.Curve.Params()
+	entl := len(uid) * 8
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+	return sm3.SumSM3Multi(
+		[]byte{byte(entl >> 8), byte(entl)},
+		uid,
+		fieldBytes(a),
+		fieldBytes(params.B),
+		fieldBytes(params.Gx),
+		fieldBytes(params.Gy),
+		fieldBytes(pub.X),
+		fieldBytes(pub.Y),
+	), nil
+}
+
+// messageDigest returns e = SM3(Z_A || M), where M is msg itself if h is
+// zero and h(msg) otherwise.
+func messageDigest(pub *PublicKey, msg, uid []byte, h crypto.Hash) ([]byte, error) {
+	za, err := ZA(pub, uid)
+	if err != nil {
+		return nil, err
+	}
+	if h != 0 {
+		if !h.Available() {
+			return nil, errors.New("sm2: requested hash function is unavailable")
+		}
+		hh := h.New()
+		hh.Write(msg)
+		msg = hh.Sum(nil)
+	}
+	return sm3.SumSM3Multi(za, msg), nil
+}
+
+// SignWithHash signs msg using the identity uid. The signed digest is
+// e = SM3(Z_A || M), where Z_A is computed with SM3 as usual and M is
+// h(msg), or msg itself when h is zero.
+//
+// Only h == 0 conforms to GB/T 32918.2; any other value yields signatures
+// that standard verifiers reject, and is provided solely for interoperating
+// with systems that pre-hash the message before the Z_A step.
+func SignWithHash(rand io.Reader, priv *PrivateKey, msg, uid []byte, h crypto.Hash) (r, s *big.Int, err error) {
+	e, err := messageDigest(&priv.PublicKey, msg, uid, h)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Sign(rand, priv, e)
+}
+
+// VerifyWithHash verifies a signature produced by SignWithHash with the
+// same uid and h.
+func VerifyWithHash(pub *PublicKey, msg, uid []byte, h crypto.Hash, r, s *big.Int) bool {
+	e, err := messageDigest(pub, msg, uid, h)
+	if err != nil {
+		return false
+	}
+	return Verify(pub, e, r, s)
+}
+
+// contextMessage binds msg to context by signing or hashing
+// M' = len(context) || context || msg, with the length as 2 big-endian
+// bytes, so that no (context, msg) pair can be reinterpreted as another.
+func contextMessage(msg, context []byte) ([]byte, error) {
+	if len(context) > 0xffff {
+		return nil, errors.New("sm2: context too long")
+	}
+	m := make([]byte, 0, 2+len(context)+len(msg))
+	m = append(m, byte(len(context)>>8), byte(len(context)))
+	m = append(m, context...)
+	return append(m, msg...), nil
+}
+
+// SignWithContext signs msg for use only within the domain named by
+// context, such as "login" or "transfer", with the default uid. A signature
+// made under one context does not verify under any other. The resulting
+// signature is a standard SM2 signature over a context-prefixed message and
+// is not interchangeable with one from SignWithHash.
+func SignWithContext(rand io.Reader, priv *PrivateKey, msg, context []byte) (r, s *big.Int, err error) {
+	m, err := contextMessage(msg, context)
+	if err != nil {
+		return nil, nil, err
+	}
+	return SignWithHash(rand, priv, m, nil, 0)
+}
+
+// VerifyWithContext verifies a signature produced by SignWithContext for
+// the same context.
+func VerifyWithContext(pub *PublicKey, msg, context []byte, r, s *big.Int) bool {
+	m, err := contextMessage(msg, context)
+	if err != nil {
+		return false
+	}
+	return VerifyWithHash(pub, m, nil, 0, r, s)
+}
+
+// VerifyLegacy verifies a signature made the way older versions of this
+// package were commonly used, by passing SM3(msg) straight to Sign without
+// the Z_A step, so that e = SM3(msg).
+//
+// Deprecated: such signatures do not conform to GB/T 32918.2 and do not
+// bind the signer's identity. Use it only to check existing data while
+// migrating to SignWithHash and VerifyWithHash.
+func VerifyLegacy(pub *PublicKey, msg []byte, r, s *big.Int) bool {
+	e := sm3.SumSM3(msg)
+	return Verify(pub, e[:], r, s)
+}
+
+// SignatureFormat identifies how the digest of a signature was computed.
+type SignatureFormat int
+
+const (
+	// FormatUnknown means no supported format matched.
+	FormatUnknown SignatureFormat = iota
+	// FormatZA is the GB/T 32918.2 form, e = SM3(Z_A || M), as made by
+	// SignWithHash with h == 0.
+	FormatZA
+	// FormatLegacy is e = SM3(M) without Z_A, as checked by VerifyLegacy.
+	FormatLegacy
+)
+
+// VerifyAny verifies the ASN.1 DER signature sig of msg by pub in either
+// the conformant Z_A form with identity uid or the legacy raw digest form,
+// trying the conformant form first, and reports which one matched. It is
+// meant for migrations where both kinds of signature are still in
+// circulation; once they are not, use VerifyWithHash.
+func VerifyAny(pub *PublicKey, msg, sig, uid []byte) (SignatureFormat, bool) {
+	r, s, err := parseSignature(sig)
+	if err != nil {
+		return FormatUnknown, false
+	}
+	if VerifyWithHash(pub, msg, uid, 0, r, s) {
+		return FormatZA, true
+	}
+	if VerifyLegacy(pub, msg, r, s) {
+		return FormatLegacy, true
+	}
+	return FormatUnknown, false
+}
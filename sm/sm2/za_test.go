@@ -0,0 +1,205 @@
+package sm2
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	_ "crypto/sha256"
+	"math/big"
+	"testing"
+
+	"crypto/sm/sm3"
+)
+
+func fromHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("bad hex " + s)
+	}
+	return n
+}
+
+// scalarReader returns a reader from which the random scalar generation in
+// this package draws exactly k.
+func scalarReader(k *big.Int) *bytes.Reader {
+	b := make([]byte, 40)
+	new(big.Int).Sub(k, one).FillBytes(b)
+	return bytes.NewReader(b)
+}
+
+// GB/T 32918 example over the recommended curve.
+var (
+	vectorD = fromHex("3945208F7B2144B13F36E38AC6D39F95889393692860B51A42FB81EF4DF7C5B8")
+	vectorK = fromHex("59276E27D506861A16680F3AD9C02DCCEF3CC1FA3CDBE4CE6D54B80DEAC1BC21")
+)
+
+func vectorKey() *PrivateKey {
+	priv := new(PrivateKey)
+	priv.Curve = P256Sm2()
+	priv.D = vectorD
+	priv.X, priv.Y = priv.Curve.ScalarBaseMult(vectorD.Bytes())
+	return priv
+}
+
+func TestSignWithHashConformant(t *testing.T) {
+	priv := vectorKey()
+	msg := []byte("message digest")
+	r, s, err := SignWithHash(scalarReader(vectorK), priv, msg, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantR := fromHex("F5A03B0648D2C4630EEAC513E1BB81A15944DA3827D5B74143AC7EACEEE720B3")
+	wantS := fromHex("B1B6AA29DF212FD8763182BC0D421CA1BB9038FD1F7F42D4840B69C485BBC1AA")
+	if r.Cmp(wantR) != 0 || s.Cmp(wantS) != 0 {
+		t.Errorf("SignWithHash = (%X, %X), want (%X, %X)", r, s, wantR, wantS)
+	}
+	if !VerifyWithHash(&priv.PublicKey, msg, []byte("1234567812345678"), 0, r, s) {
+		t.Error("VerifyWithHash failed with the explicit default uid")
+	}
+	if VerifyWithHash(&priv.PublicKey, msg, []byte("ALICE123@YAHOO.COM"), 0, r, s) {
+		t.Error("VerifyWithHash succeeded with a different uid")
+	}
+}
+
+func TestSignWithHashPrehashed(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	msg := []byte("testing")
+	r, s, err := SignWithHash(rand.Reader, priv, msg, nil, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyWithHash(&priv.PublicKey, msg, nil, crypto.SHA256, r, s) {
+		t.Error("VerifyWithHash failed for a SHA-256 pre-hashed signature")
+	}
+	if VerifyWithHash(&priv.PublicKey, msg, nil, 0, r, s) {
+		t.Error("pre-hashed signature verified as a conformant one")
+	}
+	if _, _, err := SignWithHash(rand.Reader, priv, msg, nil, crypto.MD4); err == nil {
+		t.Error("SignWithHash accepted an unavailable hash")
+	}
+	if _, _, err := SignWithHash(rand.Reader, priv, msg, make([]byte, 8192), 0); err == nil {
+		t.Error("SignWithHash accepted an oversized uid")
+	}
+}
+
+func TestSignPrehashedE(t *testing.T) {
+	priv := vectorKey()
+	msg := []byte("message digest")
+	e, err := messageDigest(&priv.PublicKey, msg, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1, s1, err := SignWithHash(scalarReader(vectorK), priv, msg, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, s2, err := SignPrehashedE(scalarReader(vectorK), priv, new(big.Int).SetBytes(e))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r1.Cmp(r2) != 0 || s1.Cmp(s2) != 0 {
+		t.Errorf("SignPrehashedE = (%X, %X), want (%X, %X)", r2, s2, r1, s1)
+	}
+	if !VerifyWithHash(&priv.PublicKey, msg, nil, 0, r2, s2) {
+		t.Error("signature from SignPrehashedE does not verify")
+	}
+	if _, _, err := SignPrehashedE(rand.Reader, priv, nil); err == nil {
+		t.Error("SignPrehashedE accepted a nil e")
+	}
+	if _, _, err := SignPrehashedE(rand.Reader, priv, big.NewInt(-1)); err == nil {
+		t.Error("SignPrehashedE accepted a negative e")
+	}
+}
+
+func TestSignWithContext(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	msg := []byte("amount=100")
+	r, s, err := SignWithContext(rand.Reader, priv, msg, []byte("login"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyWithContext(pub, msg, []byte("login"), r, s) {
+		t.Error("VerifyWithContext failed under the signing context")
+	}
+	if VerifyWithContext(pub, msg, []byte("transfer"), r, s) {
+		t.Error("signature for context login verified under context transfer")
+	}
+	if VerifyWithContext(pub, msg, nil, r, s) {
+		t.Error("signature for context login verified without a context")
+	}
+	if VerifyWithHash(pub, msg, nil, 0, r, s) {
+		t.Error("context signature verified as a plain signature")
+	}
+	// Moving bytes between context and message must not preserve validity.
+	if VerifyWithContext(pub, append([]byte("n"), msg...), []byte("logi"), r, s) {
+		t.Error("signature verified with a different context/message split")
+	}
+	if _, _, err := SignWithContext(rand.Reader, priv, msg, make([]byte, 0x10000)); err == nil {
+		t.Error("SignWithContext accepted an oversized context")
+	}
+}
+
+func TestVerifyLegacy(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	msg := []byte("historical record")
+
+	// The old calling convention: hash the message and sign the digest.
+	digest := sm3.SumSM3(msg)
+	r, s, err := Sign(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyLegacy(pub, msg, r, s) {
+		t.Error("VerifyLegacy rejected a legacy signature")
+	}
+	if VerifyWithHash(pub, msg, nil, 0, r, s) {
+		t.Error("legacy signature verified on the conformant path")
+	}
+	if VerifyLegacy(pub, []byte("altered record"), r, s) {
+		t.Error("VerifyLegacy accepted a different message")
+	}
+
+	r, s, _ = SignWithHash(rand.Reader, priv, msg, nil, 0)
+	if VerifyLegacy(pub, msg, r, s) {
+		t.Error("conformant signature verified on the legacy path")
+	}
+}
+
+func TestVerifyAny(t *testing.T) {
+	priv, _ := GenerateKey(rand.Reader)
+	pub := &priv.PublicKey
+	msg := []byte("migrating record")
+	uid := []byte("alice@example.com")
+
+	r, s, err := SignWithHash(rand.Reader, priv, msg, uid, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conformant := appendSignature(nil, r, s)
+	digest := sm3.SumSM3(msg)
+	r, s, err = Sign(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := appendSignature(nil, r, s)
+
+	if f, ok := VerifyAny(pub, msg, conformant, uid); !ok || f != FormatZA {
+		t.Errorf("conformant signature: VerifyAny = %v, %v; want FormatZA", f, ok)
+	}
+	if f, ok := VerifyAny(pub, msg, legacy, uid); !ok || f != FormatLegacy {
+		t.Errorf("legacy signature: VerifyAny = %v, %v; want FormatLegacy", f, ok)
+	}
+	if f, ok := VerifyAny(pub, msg, conformant, []byte("bob@example.com")); ok || f != FormatUnknown {
+		t.Errorf("wrong uid: VerifyAny = %v, %v", f, ok)
+	}
+	for _, sig := range [][]byte{conformant, legacy} {
+		if f, ok := VerifyAny(pub, []byte("altered record"), sig, uid); ok || f != FormatUnknown {
+			t.Errorf("altered message: VerifyAny = %v, %v", f, ok)
+		}
+	}
+	if _, ok := VerifyAny(pub, msg, []byte{0x30, 0x00}, uid); ok {
+		t.Error("VerifyAny accepted a malformed signature")
+	}
+}
@@ -0,0 +1,31 @@
+package sm3
+
+import "encoding/binary"
+
+// BloomHashes returns k indices in [0, m) for data, for setting or testing
+// bits of a Bloom filter with m bits. The indices are derived from a single
+// SM3 digest by double hashing (Kirsch and Mitzenmacher): with h1 and h2 the
+// first two 64-bit big-endian words of the digest, index i is
+// (h1 + i·h2) mod m. h2 is forced odd so that for m a power of two the
+// indices do not collapse onto a short cycle.
+//
+// It panics if k is negative or m is not in [1, 2³²].
+func BloomHashes(data []byte, k, m int) []uint32 {
+	if k < 0 {
+		panic("sm3: negative number of Bloom filter hashes")
+	}
+	if m <= 0 || uint64(m) > 1<<32 {
+		panic("sm3: Bloom filter size out of range")
+	}
+	sum := SumSM3(data)
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	mm := uint64(m)
+	h1, h2 = h1%mm, h2%mm
+	out := make([]uint32, k)
+	for i := range out {
+		out[i] = uint32(h1)
+		h1 = (h1 + h2) % mm
+	}
+	return out
+}
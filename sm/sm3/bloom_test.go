@@ -0,0 +1,61 @@
+package sm3
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestBloomHashes(t *testing.T) {
+	for _, m64 := range []int64{1, 7, 1024, 1000003, 1 << 32} {
+		if strconv.IntSize == 32 && m64 > math.MaxInt32 {
+			continue
+		}
+		m := int(m64)
+		for _, data := range []string{"", "a", "some record"} {
+			idx := BloomHashes([]byte(data), 10, m)
+			if len(idx) != 10 {
+				t.Fatalf("got %d indices, want 10", len(idx))
+			}
+			for _, v := range idx {
+				if uint64(v) >= uint64(m) {
+					t.Errorf("index %d out of [0, %d)", v, m)
+				}
+			}
+			if again := BloomHashes([]byte(data), 10, m); !reflect.DeepEqual(idx, again) {
+				t.Errorf("BloomHashes(%q, 10, %d) is not deterministic", data, m)
+			}
+		}
+	}
+
+	// For a power of two m the odd stride visits distinct positions.
+	idx := BloomHashes([]byte("x"), 16, 1<<20)
+	seen := make(map[uint32]bool)
+	for _, v := range idx {
+		if seen[v] {
+			t.Fatalf("repeated index %d in %v", v, idx)
+		}
+		seen[v] = true
+	}
+
+	if reflect.DeepEqual(BloomHashes([]byte("a"), 8, 1<<20), BloomHashes([]byte("b"), 8, 1<<20)) {
+		t.Error("different inputs gave the same indices")
+	}
+	if got := BloomHashes([]byte("a"), 0, 10); len(got) != 0 {
+		t.Errorf("k = 0 gave %v", got)
+	}
+}
+
+func TestBloomHashesPanics(t *testing.T) {
+	for _, c := range [][2]int{{-1, 10}, {3, 0}, {3, -5}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("BloomHashes(k=%d, m=%d) did not panic", c[0], c[1])
+				}
+			}()
+			BloomHashes(nil, c[0], c[1])
+		}()
+	}
+}
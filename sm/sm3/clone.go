@@ -0,0 +1,24 @@
+package sm3
+
+import "hash"
+
+// Clone returns an independent copy of d in its current state, so that a
+// common prefix can be hashed once and then finished with different
+// suffixes. Writes to either copy do not affect the other. The hash.Hash
+// values returned by New, NewWithIV and NewWithProgress all provide Clone
+// through an interface{ Clone() hash.Hash } assertion; a clone of a
+// NewWithProgress digest shares its callback.
+func (d *digest) Clone() hash.Hash {
+	c := *d
+	return &c
+}
+
+func (d *ivDigest) Clone() hash.Hash {
+	c := *d
+	return &c
+}
+
+func (d *progressDigest) Clone() hash.Hash {
+	c := *d
+	return &c
+}
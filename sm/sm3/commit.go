@@ -0,0 +1,32 @@
+package sm3
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// Commit returns a commitment to value under nonce:
+//
+//	SM3(len(value) || value || len(nonce) || nonce)
+//
+// with each length an 8 byte big-endian integer. The length prefixes make
+// the encoding injective, so no two different (value, nonce) pairs hash the
+// same input even when value || nonce is equal. The commitment only hides
+// value if nonce is secret and random; 32 bytes from crypto/rand suffice.
+func Commit(value, nonce []byte) []byte {
+	var l [8]byte
+	h := New()
+	binary.BigEndian.PutUint64(l[:], uint64(len(value)))
+	h.Write(l[:])
+	h.Write(value)
+	binary.BigEndian.PutUint64(l[:], uint64(len(nonce)))
+	h.Write(l[:])
+	h.Write(nonce)
+	return h.Sum(nil)
+}
+
+// VerifyCommit reports whether commitment was produced by Commit from value
+// and nonce. The comparison takes constant time.
+func VerifyCommit(commitment, value, nonce []byte) bool {
+	return subtle.ConstantTimeCompare(commitment, Commit(value, nonce)) == 1
+}
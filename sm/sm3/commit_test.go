@@ -0,0 +1,42 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCommit(t *testing.T) {
+	value, nonce := []byte("bid: 1200"), []byte("0123456789abcdef0123456789abcdef")
+	c := Commit(value, nonce)
+	if len(c) != Size {
+		t.Fatalf("commitment is %d bytes, want %d", len(c), Size)
+	}
+	if !VerifyCommit(c, value, nonce) {
+		t.Error("VerifyCommit rejected a valid opening")
+	}
+	if VerifyCommit(c, []byte("bid: 1300"), nonce) {
+		t.Error("VerifyCommit accepted a different value")
+	}
+	if VerifyCommit(c, value, []byte("0123456789abcdef0123456789abcdeF")) {
+		t.Error("VerifyCommit accepted a different nonce")
+	}
+	if VerifyCommit(c[:Size-1], value, nonce) {
+		t.Error("VerifyCommit accepted a truncated commitment")
+	}
+}
+
+// Every split of the same concatenation must commit differently.
+func TestCommitSplits(t *testing.T) {
+	joined := []byte("valuenonce")
+	seen := make(map[string]int)
+	for i := 0; i <= len(joined); i++ {
+		c := Commit(joined[:i], joined[i:])
+		if j, ok := seen[string(c)]; ok {
+			t.Errorf("splits at %d and %d give the same commitment", j, i)
+		}
+		seen[string(c)] = i
+	}
+	if bytes.Equal(Commit(nil, nil), Commit([]byte{0}, nil)) {
+		t.Error("an empty value and a zero byte commit alike")
+	}
+}
@@ -0,0 +1,29 @@
+package sm3
+
+// Expand returns outLen bytes of pseudo-random output derived from seed,
+// computed as MGF1 of PKCS #1 v2.2, appendix B.2.1, with SM3:
+//
+//	SM3(seed || 0x00000000) || SM3(seed || 0x00000001) || ...
+//
+// truncated to outLen bytes. Unlike the key derivation function of GB/T
+// 32918.4 the counter starts at zero, so the two do not produce the same
+// output. Expand is deterministic and suitable as a mask generation
+// function; it is not a substitute for a KDF with a secret, uniformly
+// random seed. It panics if outLen is negative.
+func Expand(seed []byte, outLen int) []byte {
+	if outLen < 0 {
+		panic("sm3: negative output length")
+	}
+	out := make([]byte, 0, outLen+Size)
+	var d digest
+	var ctr [4]byte
+	for i := uint32(0); len(out) < outLen; i++ {
+		ctr[0], ctr[1], ctr[2], ctr[3] = byte(i>>24), byte(i>>16), byte(i>>8), byte(i)
+		d.Reset()
+		d.Write(seed)
+		d.Write(ctr[:])
+		sum := d.checkSum()
+		out = append(out, sum[:]...)
+	}
+	return out[:outLen]
+}
@@ -0,0 +1,34 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	seed := []byte("mask seed")
+	long := Expand(seed, 100)
+	if len(long) != 100 {
+		t.Fatalf("Expand returned %d bytes, want 100", len(long))
+	}
+	first := SumSM3(append(append([]byte{}, seed...), 0, 0, 0, 0))
+	if !bytes.Equal(long[:Size], first[:]) {
+		t.Errorf("first block = %x, want SM3(seed || 0) = %x", long[:Size], first)
+	}
+	for _, n := range []int{0, 1, 31, 32, 33, 64, 99} {
+		got := Expand(seed, n)
+		if len(got) != n {
+			t.Errorf("Expand(seed, %d) returned %d bytes", n, len(got))
+		}
+		// Shorter outputs are prefixes of longer ones.
+		if !bytes.Equal(got, long[:n]) {
+			t.Errorf("Expand(seed, %d) is not a prefix of Expand(seed, 100)", n)
+		}
+	}
+	if !bytes.Equal(Expand(seed, 100), long) {
+		t.Error("Expand is not deterministic")
+	}
+	if bytes.Equal(Expand([]byte("mask seee"), 32), long[:32]) {
+		t.Error("different seeds gave the same output")
+	}
+}
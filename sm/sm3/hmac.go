@@ -0,0 +1,14 @@
+package sm3
+
+import (
+	"crypto/hmac"
+)
+
+// VerifyHMAC reports whether tag is the HMAC-SM3 of msg under key. The
+// comparison takes time independent of the contents of tag; a tag of the
+// wrong length is rejected.
+func VerifyHMAC(key, msg, tag []byte) bool {
+	mac := hmac.New(New, key)
+	mac.Write(msg)
+	return hmac.Equal(mac.Sum(nil), tag)
+}
@@ -0,0 +1,78 @@
+package sm3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifyHMAC(t *testing.T) {
+	key := []byte("secret key")
+	msg := []byte("message to authenticate")
+	mac := hmac.New(New, key)
+	mac.Write(msg)
+	tag := mac.Sum(nil)
+
+	if !VerifyHMAC(key, msg, tag) {
+		t.Error("VerifyHMAC rejected a valid tag")
+	}
+	bad := append([]byte{}, tag...)
+	bad[Size-1] ^= 1
+	if VerifyHMAC(key, msg, bad) {
+		t.Error("VerifyHMAC accepted a modified tag")
+	}
+	if VerifyHMAC(key, msg, tag[:Size-1]) {
+		t.Error("VerifyHMAC accepted a truncated tag")
+	}
+	if VerifyHMAC(key, msg, nil) {
+		t.Error("VerifyHMAC accepted an empty tag")
+	}
+	if VerifyHMAC([]byte("other key"), msg, tag) {
+		t.Error("VerifyHMAC accepted a tag under a different key")
+	}
+}
+
+// TestHMACKeyLength pins HMAC-SM3 for keys shorter than, equal to and longer
+// than the 64 byte block. The tags were computed with OpenSSL's HMAC-SM3
+// for key = 00 01 02 ... of each length.
+func TestHMACKeyLength(t *testing.T) {
+	msg := []byte("Sample message for keylen")
+	vectors := []struct {
+		keyLen int
+		tag    string
+	}{
+		{20, "4d95ee4ee9aefb63e3e158ef9571d736728ef3cfb8a182e47745dcae6cfc0265"},
+		{BlockSize, "bd65c238159f542bc48f0a4e37be8aded54251869288cd16567ced6704e8a79b"},
+		{100, "2551c5bb213215c99e6685490aa5a6dc87dc06c9b3df12e4a54d12b8377257b1"},
+	}
+	sum := func(key []byte) []byte {
+		mac := hmac.New(New, key)
+		mac.Write(msg)
+		return mac.Sum(nil)
+	}
+	for _, v := range vectors {
+		key := make([]byte, v.keyLen)
+		for i := range key {
+			key[i] = byte(i)
+		}
+		want, _ := hex.DecodeString(v.tag)
+		got := sum(key)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%d byte key: HMAC-SM3 = %x, want %x", v.keyLen, got, want)
+		}
+
+		// RFC 2104 normalisation: a key longer than the block is replaced
+		// by its SM3 digest, and a shorter key is padded with zeros.
+		var normalized []byte
+		if v.keyLen > BlockSize {
+			d := SumSM3(key)
+			normalized = d[:]
+		} else {
+			normalized = append(key, make([]byte, BlockSize-v.keyLen)...)
+		}
+		if !bytes.Equal(sum(normalized), want) {
+			t.Errorf("%d byte key: HMAC-SM3 differs from that of the normalised key", v.keyLen)
+		}
+	}
+}
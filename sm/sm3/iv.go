@@ -0,0 +1,27 @@
+package sm3
+
+import "hash"
+
+// ivDigest is an SM3 digest that starts from a caller supplied state.
+type ivDigest struct {
+	digest
+	iv [8]uint32
+}
+
+// NewWithIV returns a hash.Hash computing SM3 from the initial state iv
+// instead of the standard IV. Reset restores iv.
+//
+// The digests are not SM3 digests and will not match any other
+// implementation. NewWithIV is meant for separating internal hash domains;
+// use New for anything that has to interoperate.
+func NewWithIV(iv [8]uint32) hash.Hash {
+	d := &ivDigest{iv: iv}
+	d.Reset()
+	return d
+}
+
+func (d *ivDigest) Reset() {
+	d.h = d.iv
+	d.nx = 0
+	d.len = 0
+}
@@ -0,0 +1,38 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewWithIV(t *testing.T) {
+	msg := []byte("abc")
+	standard := [8]uint32{init0, init1, init2, init3, init4, init5, init6, init7}
+	want := SumSM3(msg)
+
+	h := NewWithIV(standard)
+	h.Write(msg)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("NewWithIV(standard IV) = %x, want %x", got, want)
+	}
+
+	iv := standard
+	iv[0] ^= 1
+	h = NewWithIV(iv)
+	h.Write(msg)
+	first := h.Sum(nil)
+	if bytes.Equal(first, want[:]) {
+		t.Error("custom IV produced the standard digest")
+	}
+
+	h.Reset()
+	h.Write(msg)
+	if second := h.Sum(nil); !bytes.Equal(first, second) {
+		t.Errorf("digest after Reset = %x, want %x", second, first)
+	}
+	h = NewWithIV(iv)
+	h.Write(msg)
+	if third := h.Sum(nil); !bytes.Equal(first, third) {
+		t.Errorf("second NewWithIV digest = %x, want %x", third, first)
+	}
+}
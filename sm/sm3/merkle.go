@@ -0,0 +1,93 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Merkle trees follow the construction of RFC 6962, section 2.1, with SM3
+// as the hash function. Leaf and interior node hashes are domain separated
+// by a 0x00 and 0x01 prefix respectively, so a leaf can never be passed off
+// as an interior node.
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+func merkleLeaf(leaf []byte) []byte {
+	return SumSM3Multi([]byte{merkleLeafPrefix}, leaf)
+}
+
+func merkleNode(left, right []byte) []byte {
+	return SumSM3Multi([]byte{merkleNodePrefix}, left, right)
+}
+
+// splitPoint returns the largest power of two smaller than n, for n > 1.
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// MerkleRoot returns the SM3 Merkle tree hash of leaves. The root of an
+// empty tree is the SM3 digest of the empty string.
+func MerkleRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return SumSM3Multi()
+	case 1:
+		return merkleLeaf(leaves[0])
+	}
+	k := splitPoint(len(leaves))
+	return merkleNode(MerkleRoot(leaves[:k]), MerkleRoot(leaves[k:]))
+}
+
+// MerkleProof returns the audit path proving that leaves[index] is part of
+// the tree with root MerkleRoot(leaves), ordered from the leaf upwards.
+func MerkleProof(leaves [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, errors.New("sm3: merkle leaf index out of range")
+	}
+	return merklePath(leaves, index), nil
+}
+
+func merklePath(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if index < k {
+		return append(merklePath(leaves[:k], index), MerkleRoot(leaves[k:]))
+	}
+	return append(merklePath(leaves[k:], index-k), MerkleRoot(leaves[:k]))
+}
+
+// VerifyProof reports whether proof shows that leaf is at position index in
+// a tree of size leaves with the given root, using the inclusion proof
+// verification algorithm of RFC 9162, section 2.1.3.2.
+func VerifyProof(root, leaf []byte, index, size int, proof [][]byte) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	r := merkleLeaf(leaf)
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = merkleNode(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNode(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
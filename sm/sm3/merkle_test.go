@@ -0,0 +1,56 @@
+package sm3
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestMerkleProof(t *testing.T) {
+	for size := 1; size <= 13; size++ {
+		leaves := make([][]byte, size)
+		for i := range leaves {
+			leaves[i] = []byte(fmt.Sprintf("leaf %d", i))
+		}
+		root := MerkleRoot(leaves)
+		for i := range leaves {
+			proof, err := MerkleProof(leaves, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyProof(root, leaves[i], i, size, proof) {
+				t.Errorf("size %d: proof for leaf %d does not verify", size, i)
+			}
+			if VerifyProof(root, []byte("forged"), i, size, proof) {
+				t.Errorf("size %d: proof for leaf %d verified a forged leaf", size, i)
+			}
+			if size > 1 && VerifyProof(root, leaves[i], (i+1)%size, size, proof) {
+				t.Errorf("size %d: proof for leaf %d verified at the wrong index", size, i)
+			}
+			if len(proof) > 0 {
+				forged := append([][]byte{}, proof...)
+				forged[0] = bytes.Repeat([]byte{0xaa}, Size)
+				if VerifyProof(root, leaves[i], i, size, forged) {
+					t.Errorf("size %d: forged proof for leaf %d verified", size, i)
+				}
+			}
+		}
+	}
+}
+
+func TestMerkleDomainSeparation(t *testing.T) {
+	leaves := [][]byte{[]byte("a"), []byte("b")}
+	root := MerkleRoot(leaves)
+	// An interior node presented as a leaf must not reproduce the root.
+	inner := append(merkleLeaf(leaves[0]), merkleLeaf(leaves[1])...)
+	if bytes.Equal(MerkleRoot([][]byte{inner}), root) {
+		t.Error("interior node and leaf hashes collide")
+	}
+	if _, err := MerkleProof(leaves, 2); err == nil {
+		t.Error("MerkleProof accepted an out of range index")
+	}
+	want := SumSM3(nil)
+	if !bytes.Equal(MerkleRoot(nil), want[:]) {
+		t.Error("empty tree root is not SM3 of the empty string")
+	}
+}
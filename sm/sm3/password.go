@@ -0,0 +1,106 @@
+package sm3
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Password hashes are encoded as
+//
+//	$pbkdf2-sm3$<cost>$<salt>$<key>
+//
+// where the PBKDF2-HMAC-SM3 iteration count is 2^cost and salt and key are
+// unpadded standard base64.
+const (
+	MinCost     = 10
+	MaxCost     = 30
+	DefaultCost = 16
+
+	passwordPrefix  = "$pbkdf2-sm3$"
+	passwordSaltLen = 16
+	passwordKeyLen  = Size
+)
+
+// HashPassword returns a self-describing hash of password using
+// PBKDF2-HMAC-SM3 with a fresh random salt and 2^cost iterations.
+func HashPassword(password []byte, cost int) (string, error) {
+	if cost < MinCost || cost > MaxCost {
+		return "", fmt.Errorf("sm3: password hashing cost %d outside [%d, %d]", cost, MinCost, MaxCost)
+	}
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2(password, salt, 1<<uint(cost), passwordKeyLen)
+	enc := base64.RawStdEncoding
+	return passwordPrefix + strconv.Itoa(cost) + "$" + enc.EncodeToString(salt) + "$" + enc.EncodeToString(key), nil
+}
+
+// VerifyPassword reports whether password matches a hash produced by
+// HashPassword.
+func VerifyPassword(password []byte, encoded string) bool {
+	cost, salt, key, err := parsePasswordHash(encoded)
+	if err != nil {
+		return false
+	}
+	got := pbkdf2(password, salt, 1<<uint(cost), len(key))
+	return subtle.ConstantTimeCompare(got, key) == 1
+}
+
+func parsePasswordHash(encoded string) (cost int, salt, key []byte, err error) {
+	errFormat := errors.New("sm3: malformed password hash")
+	if !strings.HasPrefix(encoded, passwordPrefix) {
+		return 0, nil, nil, errFormat
+	}
+	parts := strings.Split(encoded[len(passwordPrefix):], "$")
+	if len(parts) != 3 {
+		return 0, nil, nil, errFormat
+	}
+	// Only the form HashPassword writes is accepted, so that "+10" or
+	// "010" cannot give one password several valid encodings.
+	cost, err = strconv.Atoi(parts[0])
+	if err != nil || strconv.Itoa(cost) != parts[0] || cost < MinCost || cost > MaxCost {
+		return 0, nil, nil, errFormat
+	}
+	enc := base64.RawStdEncoding
+	if salt, err = enc.DecodeString(parts[1]); err != nil {
+		return 0, nil, nil, errFormat
+	}
+	if key, err = enc.DecodeString(parts[2]); err != nil || len(key) == 0 {
+		return 0, nil, nil, errFormat
+	}
+	return cost, salt, key, nil
+}
+
+// pbkdf2 derives keyLen bytes from password and salt with PBKDF2 (RFC 8018,
+// section 5.2) using HMAC-SM3 as the pseudorandom function.
+func pbkdf2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(New, password)
+	out := make([]byte, 0, keyLen+Size)
+	var u, t [Size]byte
+	var ctr [4]byte
+	for block := uint32(1); len(out) < keyLen; block++ {
+		ctr[0], ctr[1], ctr[2], ctr[3] = byte(block>>24), byte(block>>16), byte(block>>8), byte(block)
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(ctr[:])
+		prf.Sum(u[:0])
+		t = u
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u[:])
+			prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		out = append(out, t[:]...)
+	}
+	return out[:keyLen]
+}
@@ -0,0 +1,66 @@
+package sm3
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	password := []byte("correct horse battery staple")
+	h1, err := HashPassword(password, MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(h1, "$pbkdf2-sm3$10$") {
+		t.Errorf("unexpected encoding %q", h1)
+	}
+	if !VerifyPassword(password, h1) {
+		t.Error("VerifyPassword rejected the right password")
+	}
+	if VerifyPassword([]byte("correct horse battery stapler"), h1) {
+		t.Error("VerifyPassword accepted a wrong password")
+	}
+
+	h2, err := HashPassword(password, MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 == h2 {
+		t.Error("hashing the same password twice gave the same result")
+	}
+	if strings.Split(h1, "$")[3] == strings.Split(h2, "$")[3] {
+		t.Error("hashing the same password twice reused the salt")
+	}
+
+	for _, cost := range []int{MinCost - 1, MaxCost + 1} {
+		if _, err := HashPassword(password, cost); err == nil {
+			t.Errorf("HashPassword accepted cost %d", cost)
+		}
+	}
+	for _, bad := range []string{
+		"",
+		"$pbkdf2-sm3$10$abc",
+		"$pbkdf2-sm3$99$" + strings.SplitN(h1, "$", 4)[3],
+		"$pbkdf2-sm3$+10$" + strings.SplitN(h1, "$", 4)[3],
+		"$pbkdf2-sm3$010$" + strings.SplitN(h1, "$", 4)[3],
+		"$pbkdf2-sha256$" + h1[len("$pbkdf2-sm3$"):],
+		h1[:len(h1)-1] + "!",
+	} {
+		if VerifyPassword(password, bad) {
+			t.Errorf("VerifyPassword accepted %q", bad)
+		}
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	// Lengths that are not a multiple of the digest size exercise the
+	// final partial block, which must be a prefix of the longer output.
+	long := pbkdf2([]byte("password"), []byte("salt"), 2, 2*Size+5)
+	short := pbkdf2([]byte("password"), []byte("salt"), 2, Size+1)
+	if string(long[:len(short)]) != string(short) {
+		t.Error("shorter PBKDF2 output is not a prefix of the longer one")
+	}
+	if string(pbkdf2([]byte("password"), []byte("salt"), 1, Size)) == string(pbkdf2([]byte("password"), []byte("salt"), 2, Size)) {
+		t.Error("iteration count does not affect the output")
+	}
+}
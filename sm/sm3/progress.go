@@ -0,0 +1,40 @@
+package sm3
+
+import "hash"
+
+// ProgressInterval is the number of bytes between two invocations of the
+// callback passed to NewWithProgress.
+const ProgressInterval = 1 << 20
+
+// progressDigest is an SM3 digest that reports how much has been written.
+type progressDigest struct {
+	digest
+	cb   func(bytesProcessed int64)
+	next uint64
+}
+
+// NewWithProgress returns an SM3 hash.Hash that calls cb with the total
+// number of bytes written so far each time another ProgressInterval bytes
+// have been written since the last Reset. cb runs synchronously inside
+// Write and does not affect the digest.
+func NewWithProgress(cb func(bytesProcessed int64)) hash.Hash {
+	d := &progressDigest{cb: cb}
+	d.Reset()
+	return d
+}
+
+func (d *progressDigest) Reset() {
+	d.digest.Reset()
+	d.next = ProgressInterval
+}
+
+func (d *progressDigest) Write(p []byte) (int, error) {
+	n, err := d.digest.Write(p)
+	for d.len >= d.next {
+		if d.cb != nil {
+			d.cb(int64(d.len))
+		}
+		d.next += ProgressInterval
+	}
+	return n, err
+}
@@ -0,0 +1,41 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewWithProgress(t *testing.T) {
+	data := bytes.Repeat([]byte("progress"), (3*ProgressInterval+100)/8)
+	var calls []int64
+	h := NewWithProgress(func(n int64) { calls = append(calls, n) })
+	// Uneven writes make the interval boundaries fall inside a write.
+	for rest := data; len(rest) > 0; {
+		n := 100003
+		if n > len(rest) {
+			n = len(rest)
+		}
+		h.Write(rest[:n])
+		rest = rest[n:]
+	}
+	want := SumSM3(data)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("digest = %x, want %x", got, want)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("callback fired %d times, want 3", len(calls))
+	}
+	for i, n := range calls {
+		if n < int64(i+1)*ProgressInterval || (i > 0 && n <= calls[i-1]) {
+			t.Errorf("callback %d reported %d bytes", i, n)
+		}
+	}
+
+	// A single large write still reports every interval it crosses.
+	calls = nil
+	h.Reset()
+	h.Write(data)
+	if len(calls) != 3 {
+		t.Errorf("callback fired %d times after Reset, want 3", len(calls))
+	}
+}
@@ -0,0 +1,37 @@
+package sm3
+
+import (
+	"crypto/hmac"
+)
+
+// Ratchet is a symmetric key ratchet built on HMAC-SM3. Each call to Next
+// derives a message key from the current chain key and then replaces the
+// chain key with a one-way function of itself, so a compromise of the
+// ratchet state does not reveal keys returned earlier.
+//
+// For chain key CK, the message key is HMAC-SM3(CK, 0x01) and the next
+// chain key is HMAC-SM3(CK, 0x02).
+type Ratchet struct {
+	chain [Size]byte
+}
+
+// NewRatchet returns a Ratchet whose first chain key is HMAC-SM3(root, 0x02),
+// so the root key itself is never used as a chain key.
+func NewRatchet(root []byte) *Ratchet {
+	r := new(Ratchet)
+	ratchetStep(r.chain[:0], root, 0x02)
+	return r
+}
+
+// Next returns the next 32 byte message key and advances the chain.
+func (r *Ratchet) Next() []byte {
+	key := ratchetStep(nil, r.chain[:], 0x01)
+	ratchetStep(r.chain[:0], r.chain[:], 0x02)
+	return key
+}
+
+func ratchetStep(dst, key []byte, label byte) []byte {
+	mac := hmac.New(New, key)
+	mac.Write([]byte{label})
+	return mac.Sum(dst)
+}
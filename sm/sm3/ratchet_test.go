@@ -0,0 +1,48 @@
+package sm3
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRatchet(t *testing.T) {
+	const n = 50
+	root := []byte("session root key")
+	a, b := NewRatchet(root), NewRatchet(root)
+
+	seen := make(map[string]bool)
+	var keys [][]byte
+	for i := 0; i < n; i++ {
+		k := a.Next()
+		if len(k) != Size {
+			t.Fatalf("key %d is %d bytes, want %d", i, len(k), Size)
+		}
+		if !bytes.Equal(k, b.Next()) {
+			t.Fatalf("key %d differs between ratchets with the same root", i)
+		}
+		if seen[string(k)] {
+			t.Fatalf("key %d repeats an earlier key", i)
+		}
+		seen[string(k)] = true
+		keys = append(keys, k)
+	}
+
+	if bytes.Equal(NewRatchet([]byte("other root")).Next(), keys[0]) {
+		t.Error("different roots produced the same first key")
+	}
+
+	// Everything derivable from the current state lies ahead of it: running
+	// a ratchet forward from a copy of the chain key never yields an earlier
+	// message key, and the state does not contain one.
+	state := *a
+	for _, k := range keys {
+		if bytes.Equal(state.chain[:], k) {
+			t.Fatal("ratchet state equals an earlier message key")
+		}
+	}
+	for i := 0; i < n; i++ {
+		if seen[string(state.Next())] {
+			t.Fatal("ratchet state led back to an earlier key")
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package sm3
+
+import "io"
+
+// readFromBufSize is the chunk size used by ReadFrom, a multiple of
+// BlockSize.
+const readFromBufSize = 32 * 1024
+
+// ReadFrom reads from r until io.EOF or an error and hashes everything it
+// reads. It returns the number of bytes hashed and any error other than
+// io.EOF. Bytes read before an error are still part of the digest.
+//
+// ReadFrom makes the digests returned by New implement io.ReaderFrom, so
+// io.Copy feeds them directly.
+func (d *digest) ReadFrom(r io.Reader) (int64, error) {
+	return readFrom(d, r)
+}
+
+func (d *progressDigest) ReadFrom(r io.Reader) (int64, error) {
+	return readFrom(d, r)
+}
+
+// readFrom copies r into w, which never fails, in readFromBufSize chunks.
+func readFrom(w io.Writer, r io.Reader) (n int64, err error) {
+	buf := make([]byte, readFromBufSize)
+	for {
+		m, rerr := r.Read(buf)
+		if m > 0 {
+			w.Write(buf[:m])
+			n += int64(m)
+		}
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
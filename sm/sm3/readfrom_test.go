@@ -0,0 +1,75 @@
+package sm3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type failingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, f.err
+	}
+	return n, err
+}
+
+func TestReadFrom(t *testing.T) {
+	data := bytes.Repeat([]byte("abcdefg"), 20000)
+	want := SumSM3(data)
+
+	h := New()
+	rf, ok := h.(io.ReaderFrom)
+	if !ok {
+		t.Fatal("SM3 digest does not implement io.ReaderFrom")
+	}
+	n, err := rf.ReadFrom(bytes.NewReader(data))
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("ReadFrom = %d, %v; want %d, nil", n, err, len(data))
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("digest after ReadFrom = %x, want %x", got, want)
+	}
+
+	h.Reset()
+	if n, err := io.Copy(h, bytes.NewReader(data)); err != nil || n != int64(len(data)) {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("digest after io.Copy = %x, want %x", got, want)
+	}
+}
+
+func TestReadFromError(t *testing.T) {
+	data := bytes.Repeat([]byte{0x5a}, 1000)
+	errBroken := errors.New("connection reset")
+	h := New()
+	n, err := h.(io.ReaderFrom).ReadFrom(&failingReader{bytes.NewReader(data), errBroken})
+	if err != errBroken {
+		t.Fatalf("ReadFrom error = %v, want %v", err, errBroken)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("ReadFrom read %d bytes, want %d", n, len(data))
+	}
+	want := SumSM3(data)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("digest of the bytes read before the error = %x, want %x", got, want)
+	}
+}
+
+func TestReadFromProgress(t *testing.T) {
+	var calls int
+	h := NewWithProgress(func(int64) { calls++ })
+	if _, err := h.(io.ReaderFrom).ReadFrom(bytes.NewReader(make([]byte, 2*ProgressInterval))); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("progress callback ran %d times, want 2", calls)
+	}
+}
@@ -144,3 +144,41 @@ func SumSM3(data []byte) [Size]byte {
 	d.Write(data)
 	return d.checkSum()
 }
+
+// SumSM3Multi returns the SM3 checksum of the concatenation of parts,
+// without joining them into a single buffer first.
+func SumSM3Multi(parts ...[]byte) []byte {
+	var d digest
+	d.Reset()
+	for _, p := range parts {
+		d.Write(p)
+	}
+	sum := d.checkSum()
+	return sum[:]
+}
+
+// SumSM3N returns the first n bytes of the SM3 checksum of data, for use as
+// a short integrity code. Truncation reduces security: an n byte output
+// offers at most 8n bits of resistance to forgery and 4n bits of collision
+// resistance, so n should be chosen as large as the protocol allows.
+// SumSM3N panics if n is not in the range [1, Size].
+func SumSM3N(data []byte, n int) []byte {
+	if n < 1 || n > Size {
+		panic("sm3: truncated digest length out of range")
+	}
+	sum := SumSM3(data)
+	return sum[:n]
+}
+
+// SumSafe returns SM3(SM3(data)). Because the outer hash is computed over a
+// fixed-length value, the result cannot be extended the way a plain
+// Merkle-Damgård digest can: knowing SumSafe(m) does not let anyone compute
+// SumSafe(m || pad || suffix). It is meant for commitments and other uses
+// of a bare hash that might otherwise be exposed to length extension. It
+// gives no additional collision resistance over SM3, and its output is not
+// interchangeable with SumSM3.
+func SumSafe(data []byte) []byte {
+	inner := SumSM3(data)
+	outer := SumSM3(inner[:])
+	return outer[:]
+}
@@ -7,6 +7,7 @@
 package sm3
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 )
@@ -36,6 +37,42 @@ func TestGolden(t *testing.T) {
 	}
 }
 
+func TestSumSM3Multi(t *testing.T) {
+	parts := [][]byte{
+		[]byte("He who has a shady past "),
+		{},
+		[]byte("knows that nice guys "),
+		bytes.Repeat([]byte("x"), 100),
+		[]byte("finish last."),
+	}
+	for i := 0; i <= len(parts); i++ {
+		want := SumSM3(bytes.Join(parts[:i], nil))
+		if got := SumSM3Multi(parts[:i]...); !bytes.Equal(got, want[:]) {
+			t.Errorf("SumSM3Multi(parts[:%d]) = %x, want %x", i, got, want)
+		}
+	}
+}
+
+func TestSumSM3N(t *testing.T) {
+	data := []byte("The days of the digital watch are numbered.")
+	full := SumSM3(data)
+	for n := 1; n <= Size; n++ {
+		if got := SumSM3N(data, n); !bytes.Equal(got, full[:n]) {
+			t.Errorf("SumSM3N(data, %d) = %x, want %x", n, got, full[:n])
+		}
+	}
+	for _, n := range []int{-1, 0, Size + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SumSM3N(data, %d) did not panic", n)
+				}
+			}()
+			SumSM3N(data, n)
+		}()
+	}
+}
+
 func TestPnPanic(t *testing.T) {
 		var buf = make([]byte, 4000)
 
@@ -81,3 +118,19 @@ func BenchmarkHash1K(b *testing.B) {
 func BenchmarkHash8K(b *testing.B) {
 	benchmarkSize(b, 8192)
 }
+
+func TestSumSafe(t *testing.T) {
+	data := []byte("commitment")
+	got := SumSafe(data)
+	if !bytes.Equal(got, SumSafe(data)) {
+		t.Error("SumSafe is not deterministic")
+	}
+	single := SumSM3(data)
+	if bytes.Equal(got, single[:]) {
+		t.Error("SumSafe equals single SM3")
+	}
+	double := SumSM3(single[:])
+	if !bytes.Equal(got, double[:]) {
+		t.Errorf("SumSafe = %x, want SM3(SM3(data)) = %x", got, double)
+	}
+}
@@ -0,0 +1,87 @@
+package sm3
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// writeChunks writes data to a new digest in chunks of the given sizes,
+// the last chunk taking whatever remains, and returns the digest.
+func writeChunks(data []byte, sizes []int) []byte {
+	h := New()
+	for _, n := range sizes {
+		if n > len(data) {
+			n = len(data)
+		}
+		h.Write(data[:n])
+		data = data[n:]
+	}
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// TestChunkBoundaries checks that the digest does not depend on how the
+// input is split across Write calls, whatever the position of the splits
+// relative to the 64 byte block boundary.
+func TestChunkBoundaries(t *testing.T) {
+	data := make([]byte, 2*BlockSize+1)
+	rand.New(rand.NewSource(1)).Read(data)
+	for l := 0; l <= len(data); l++ {
+		msg := data[:l]
+		want := SumSM3(msg)
+
+		// All single byte writes.
+		h := New()
+		for i := range msg {
+			h.Write(msg[i : i+1])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Fatalf("%d bytes written one at a time: got %x, want %x", l, got, want)
+		}
+		// Every split into two and three pieces.
+		for i := 0; i <= l; i++ {
+			if got := writeChunks(msg, []int{i}); !bytes.Equal(got, want[:]) {
+				t.Fatalf("%d bytes split at %d: got %x, want %x", l, i, got, want)
+			}
+			for j := 0; i+j <= l && j <= BlockSize+1; j++ {
+				if got := writeChunks(msg, []int{i, j}); !bytes.Equal(got, want[:]) {
+					t.Fatalf("%d bytes split at %d and %d: got %x, want %x", l, i, i+j, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestRandomChunks writes longer inputs in random chunk sizes, calling Sum
+// part way through as a streaming caller reporting progress might.
+func TestRandomChunks(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for iter := 0; iter < 200; iter++ {
+		msg := make([]byte, rng.Intn(5000))
+		rng.Read(msg)
+		want := SumSM3(msg)
+
+		h := New()
+		for rest := msg; len(rest) > 0; {
+			n := rng.Intn(3*BlockSize) + 1
+			if rng.Intn(4) == 0 {
+				n = BlockSize
+			}
+			if n > len(rest) {
+				n = len(rest)
+			}
+			h.Write(rest[:n])
+			rest = rest[n:]
+			if rng.Intn(8) == 0 {
+				done := len(msg) - len(rest)
+				if got, want := h.Sum(nil), SumSM3(msg[:done]); !bytes.Equal(got, want[:]) {
+					t.Fatalf("Sum after %d of %d bytes: got %x, want %x", done, len(msg), got, want)
+				}
+			}
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Fatalf("%d bytes in random chunks: got %x, want %x", len(msg), got, want)
+		}
+	}
+}
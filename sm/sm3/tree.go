@@ -0,0 +1,147 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HashTreeOptions changes how HashTreeWithOptions walks a tree.
+type HashTreeOptions struct {
+	// FollowSymlinks makes symbolic links be hashed as the file or
+	// directory they point to, under the path of the link. A link to a
+	// directory that is already being walked, which would otherwise loop
+	// forever, is skipped. A link that cannot be resolved is an error.
+	FollowSymlinks bool
+}
+
+// HashTree walks the directory tree at root and returns the hex SM3 digest
+// of every regular file, keyed by its slash-separated path relative to
+// root. Files are read in a streaming fashion, so their size does not
+// matter. Symbolic links are not followed and, like other non-regular
+// files, do not appear in the result; use HashTreeWithOptions to follow
+// them.
+func HashTree(root string) (map[string]string, error) {
+	return HashTreeWithOptions(root, HashTreeOptions{})
+}
+
+// HashTreeWithOptions is HashTree with the behaviour set by opts.
+func HashTreeWithOptions(root string, opts HashTreeOptions) (map[string]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.New("sm3: HashTree root is not a directory")
+	}
+	manifest := make(map[string]string)
+	if err := hashDir(manifest, root, "", []os.FileInfo{info}, opts); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// hashDir adds the files below dir, whose path relative to the root is
+// rel, to manifest. ancestors holds the directories from the root down to
+// dir, for detecting symlink loops.
+func hashDir(manifest map[string]string, dir, rel string, ancestors []os.FileInfo, opts HashTreeOptions) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		name := e.Name()
+		if rel != "" {
+			name = rel + "/" + name
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !opts.FollowSymlinks {
+				continue
+			}
+			if info, err = os.Stat(path); err != nil {
+				return err
+			}
+		}
+		switch {
+		case info.IsDir():
+			loop := false
+			for _, a := range ancestors {
+				if os.SameFile(a, info) {
+					loop = true
+					break
+				}
+			}
+			if loop {
+				continue
+			}
+			if err := hashDir(manifest, path, name, append(ancestors, info), opts); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			sum, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			manifest[name] = sum
+		}
+	}
+	return nil
+}
+
+// ManifestDigest returns the hex SM3 digest of manifest, as produced by
+// HashTree, serialised as one "digest  path\n" line per file in sorted path
+// order, the format of sha256sum and similar tools. Equal trees give equal
+// digests regardless of walk order.
+//
+// As in sha256sum, a path containing a backslash, newline or carriage
+// return is written with those characters escaped as \\, \n and \r and
+// its line is prefixed with a backslash, so no path can forge the lines
+// of other files. ManifestDigest panics if a digest is not 64 hex
+// digits.
+func ManifestDigest(manifest map[string]string) string {
+	paths := make([]string, 0, len(manifest))
+	for p := range manifest {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := New()
+	for _, p := range paths {
+		d := manifest[p]
+		if b, err := hex.DecodeString(d); err != nil || len(b) != Size {
+			panic("sm3: manifest digest is not 64 hex digits")
+		}
+		io.WriteString(h, manifestLine(d, p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var manifestEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+func manifestLine(digest, path string) string {
+	if strings.ContainsAny(path, "\\\n\r") {
+		return `\` + digest + "  " + manifestEscaper.Replace(path) + "\n"
+	}
+	return digest + "  " + path + "\n"
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,106 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHashTree(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.txt":         "alpha",
+		"sub/b.txt":     "bravo",
+		"sub/deep/c.md": "",
+	}
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outside := filepath.Join(t.TempDir(), "secret")
+	os.WriteFile(outside, []byte("outside the tree"), 0o644)
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+
+	manifest, err := HashTree(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]string)
+	for name, body := range files {
+		sum := SumSM3([]byte(body))
+		want[name] = hex.EncodeToString(sum[:])
+	}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("HashTree = %v, want %v", manifest, want)
+	}
+
+	again, _ := HashTree(root)
+	if ManifestDigest(manifest) != ManifestDigest(again) {
+		t.Error("root digest is not stable")
+	}
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("alphA"), 0o644)
+	changed, _ := HashTree(root)
+	if ManifestDigest(changed) == ManifestDigest(manifest) {
+		t.Error("root digest did not change with a file")
+	}
+
+	if _, err := HashTree(filepath.Join(root, "missing")); err == nil {
+		t.Error("HashTree succeeded on a missing root")
+	}
+
+	// Following links hashes the target under the link's path, and a link
+	// back up the tree does not loop.
+	if err := os.Symlink(root, filepath.Join(root, "sub", "up")); err != nil {
+		t.Fatal(err)
+	}
+	followed, err := HashTreeWithOptions(root, HashTreeOptions{FollowSymlinks: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := SumSM3([]byte("outside the tree"))
+	changed["link"] = hex.EncodeToString(sum[:])
+	if !reflect.DeepEqual(followed, changed) {
+		t.Errorf("HashTreeWithOptions = %v, want %v", followed, changed)
+	}
+	os.Symlink(filepath.Join(root, "nowhere"), filepath.Join(root, "broken"))
+	if _, err := HashTreeWithOptions(root, HashTreeOptions{FollowSymlinks: true}); err == nil {
+		t.Error("a broken link was not reported")
+	}
+}
+
+func TestManifestDigestEscaping(t *testing.T) {
+	d := func(s string) string {
+		sum := SumSM3([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	// Written out unescaped, the newline in this one path would produce
+	// exactly the two lines of forged.
+	crafted := map[string]string{"a\n" + d("x") + "  b": d("y")}
+	forged := map[string]string{"a": d("y"), "b": d("x")}
+	if ManifestDigest(crafted) == ManifestDigest(forged) {
+		t.Error("a path with a newline forged the manifest of another tree")
+	}
+
+	if got, want := manifestLine(d("y"), "a\\b\nc"), "\\"+d("y")+"  a\\\\b\\nc\n"; got != want {
+		t.Errorf("manifestLine = %q, want %q", got, want)
+	}
+	if got, want := manifestLine(d("y"), "plain name"), d("y")+"  plain name\n"; got != want {
+		t.Errorf("manifestLine = %q, want %q", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("ManifestDigest accepted a digest that is not hex")
+		}
+	}()
+	ManifestDigest(map[string]string{"a": d("y")[:63] + "\n"})
+}
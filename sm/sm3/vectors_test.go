@@ -0,0 +1,31 @@
+package sm3
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// Examples from GB/T 32905-2016, appendix A.
+var standardVectors = []struct {
+	in  []byte
+	out string
+}{
+	{[]byte("abc"), "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+	{bytes.Repeat([]byte("abcd"), 16), "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+}
+
+func TestStandardVectors(t *testing.T) {
+	for _, v := range standardVectors {
+		if got := fmt.Sprintf("%x", SumSM3(v.in)); got != v.out {
+			t.Errorf("SumSM3(%q) = %s, want %s", v.in, got, v.out)
+		}
+		h := New()
+		for i := range v.in {
+			h.Write(v.in[i : i+1])
+		}
+		if got := fmt.Sprintf("%x", h.Sum(nil)); got != v.out {
+			t.Errorf("byte-wise SM3(%q) = %s, want %s", v.in, got, v.out)
+		}
+	}
+}
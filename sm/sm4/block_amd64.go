@@ -0,0 +1,28 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+package sm4
+
+// sm4T combines the S-box and the linear transform L of the round
+// function: sm4T[j][a] = L(Sbox(a) << (24 - 8j)), so that one round is
+// four table lookups. It is read by cryptBlockAsm.
+var sm4T [4][256]uint32
+
+func init() {
+	for a := 0; a < 256; a++ {
+		s := uint32(scSbox(byte(a)))
+		for j := 0; j < 4; j++ {
+			sm4T[j][a] = l(s << uint(24-8*j))
+		}
+	}
+}
+
+//go:noescape
+func cryptBlockAsm(rk *[32]uint32, dst, src *byte)
+
+// cryptBlock runs the 32 rounds of SM4 over src using the round keys rk
+// and writes the result to dst. dst and src may overlap entirely.
+func cryptBlock(rk *[32]uint32, dst, src []byte) {
+	_, _ = dst[BlockSize-1], src[BlockSize-1]
+	cryptBlockAsm(rk, &dst[0], &src[0])
+}
@@ -0,0 +1,60 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+#include "textflag.h"
+
+// ROUND computes X0 ^= T(X1 ^ X2 ^ X3 ^ rk[OFF/4]) with the lookup tables
+// of sm4T, whose address is in R8. R11 and R12 are clobbered.
+#define ROUND(X0, X1, X2, X3, OFF) \
+	MOVL X1, R11 \
+	XORL X2, R11 \
+	XORL X3, R11 \
+	XORL OFF(SI), R11 \
+	MOVBLZX R11B, R12 \
+	XORL 3072(R8)(R12*4), X0 \
+	SHRL $8, R11 \
+	MOVBLZX R11B, R12 \
+	XORL 2048(R8)(R12*4), X0 \
+	SHRL $8, R11 \
+	MOVBLZX R11B, R12 \
+	XORL 1024(R8)(R12*4), X0 \
+	SHRL $8, R11 \
+	XORL 0(R8)(R11*4), X0
+
+// func cryptBlockAsm(rk *[32]uint32, dst, src *byte)
+TEXT ·cryptBlockAsm(SB), NOSPLIT, $0-24
+	MOVQ rk+0(FP), SI
+	MOVQ dst+8(FP), DI
+	MOVQ src+16(FP), DX
+	LEAQ ·sm4T(SB), R8
+
+	MOVL 0(DX), AX
+	BSWAPL AX
+	MOVL 4(DX), BX
+	BSWAPL BX
+	MOVL 8(DX), CX
+	BSWAPL CX
+	MOVL 12(DX), R9
+	BSWAPL R9
+
+	MOVQ $8, R10
+
+loop:
+	ROUND(AX, BX, CX, R9, 0)
+	ROUND(BX, CX, R9, AX, 4)
+	ROUND(CX, R9, AX, BX, 8)
+	ROUND(R9, AX, BX, CX, 12)
+	ADDQ $16, SI
+	DECQ R10
+	JNZ loop
+
+	// The output is the last four state words in reverse order.
+	BSWAPL R9
+	MOVL R9, 0(DI)
+	BSWAPL CX
+	MOVL CX, 4(DI)
+	BSWAPL BX
+	MOVL BX, 8(DI)
+	BSWAPL AX
+	MOVL AX, 12(DI)
+	RET
@@ -0,0 +1,10 @@
+//go:build !amd64 || purego
+// +build !amd64 purego
+
+package sm4
+
+// cryptBlock runs the 32 rounds of SM4 over src using the round keys rk
+// and writes the result to dst. dst and src may overlap entirely.
+func cryptBlock(rk *[32]uint32, dst, src []byte) {
+	cryptBlockGeneric(rk, dst, src)
+}
@@ -0,0 +1,54 @@
+package sm4
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// TestCryptBlockMatchesGeneric checks the block function selected for this
+// platform against the portable implementation.
+func TestCryptBlockMatchesGeneric(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	key := make([]byte, BlockSize)
+	src := make([]byte, BlockSize)
+	got := make([]byte, BlockSize)
+	want := make([]byte, BlockSize)
+	for i := 0; i < 1000; i++ {
+		r.Read(key)
+		r.Read(src)
+		rk := keyExp(keyToUint32(key))
+		if i%2 == 1 {
+			rk = rk_swap(rk)
+		}
+		cryptBlock(&rk, got, src)
+		cryptBlockGeneric(&rk, want, src)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("key %x, input %x: cryptBlock = %x, generic = %x", key, src, got, want)
+		}
+		// In place.
+		copy(got, src)
+		cryptBlock(&rk, got, got)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("key %x, input %x: in-place cryptBlock = %x, want %x", key, src, got, want)
+		}
+	}
+}
+
+func BenchmarkCryptBlock(b *testing.B) {
+	rk := keyExp(keyToUint32([]byte("1234567890abcdef")))
+	buf := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		cryptBlock(&rk, buf, buf)
+	}
+}
+
+func BenchmarkCryptBlockGeneric(b *testing.B) {
+	rk := keyExp(keyToUint32([]byte("1234567890abcdef")))
+	buf := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		cryptBlockGeneric(&rk, buf, buf)
+	}
+}
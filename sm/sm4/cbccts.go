@@ -0,0 +1,134 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+// CBCCTS is SM4 in CBC mode with ciphertext stealing, variant CS3 of the
+// NIST SP 800-38A addendum, which is also the CBC-CTS of RFC 3962 (Kerberos).
+// The ciphertext is exactly as long as the plaintext, and no padding is
+// added. Any length of at least one block is accepted.
+//
+// CS3 always swaps the last two ciphertext blocks, even when the input is
+// a whole number of blocks. A single block input is plain CBC.
+//
+// Each call to Encrypt or Decrypt handles one complete message starting
+// from the IV given to NewCBCCTS. Like plain CBC, CBC-CTS has no integrity
+// protection; see Sm4CbcAEAD.
+type CBCCTS struct {
+	b  cipher.Block
+	iv [BlockSize]byte
+}
+
+var errCTSShort = errors.New("sm4: CBC-CTS input shorter than one block")
+
+// NewCBCCTS returns a CBCCTS for key and the 16 byte iv.
+func NewCBCCTS(key, iv []byte) (*CBCCTS, error) {
+	if len(iv) != BlockSize {
+		return nil, errors.New("sm4: IV length must equal block size")
+	}
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	c := &CBCCTS{b: b}
+	copy(c.iv[:], iv)
+	return c, nil
+}
+
+// Encrypt encrypts src into dst, which must be at least as long. dst and
+// src must overlap entirely or not at all.
+func (c *CBCCTS) Encrypt(dst, src []byte) error {
+	return ctsEncrypt(c.b, c.iv[:], dst, src)
+}
+
+// Decrypt decrypts src into dst, which must be at least as long. dst and
+// src must overlap entirely or not at all.
+func (c *CBCCTS) Decrypt(dst, src []byte) error {
+	return ctsDecrypt(c.b, c.iv[:], dst, src)
+}
+
+// ctsSplit returns the length of the leading part handled as plain CBC and
+// the length d, 1 to BlockSize, of the final, possibly partial, block.
+func ctsSplit(n int) (head, d int) {
+	d = n % BlockSize
+	if d == 0 {
+		d = BlockSize
+	}
+	return n - d - BlockSize, d
+}
+
+func ctsEncrypt(b cipher.Block, iv, dst, src []byte) error {
+	n := len(src)
+	if n < BlockSize {
+		return errCTSShort
+	}
+	if len(dst) < n {
+		return errors.New("sm4: CBC-CTS output smaller than input")
+	}
+	if n == BlockSize {
+		cipher.NewCBCEncrypter(b, iv).CryptBlocks(dst[:n], src)
+		return nil
+	}
+	head, d := ctsSplit(n)
+
+	// Read the final partial block before dst overwrites it.
+	var last [BlockSize]byte
+	copy(last[:], src[head+BlockSize:])
+
+	// C(n-1) is the ordinary CBC output for the last full block.
+	cipher.NewCBCEncrypter(b, iv).CryptBlocks(dst[:head+BlockSize], src[:head+BlockSize])
+	var cn1 [BlockSize]byte
+	copy(cn1[:], dst[head:head+BlockSize])
+
+	// C(n) = E(C(n-1) xor (P(n) || zeros)); the output ends with C(n)
+	// followed by the first d bytes of C(n-1).
+	subtle.XORBytes(last[:], last[:], cn1[:])
+	b.Encrypt(dst[head:head+BlockSize], last[:])
+	copy(dst[head+BlockSize:n], cn1[:d])
+	return nil
+}
+
+func ctsDecrypt(b cipher.Block, iv, dst, src []byte) error {
+	n := len(src)
+	if n < BlockSize {
+		return errCTSShort
+	}
+	if len(dst) < n {
+		return errors.New("sm4: CBC-CTS output smaller than input")
+	}
+	if n == BlockSize {
+		cipher.NewCBCDecrypter(b, iv).CryptBlocks(dst[:n], src)
+		return nil
+	}
+	head, d := ctsSplit(n)
+
+	// Copy out everything the last two blocks depend on before dst, which
+	// may be src, is written.
+	var cn, cn1, prev [BlockSize]byte
+	copy(cn[:], src[head:head+BlockSize])
+	copy(cn1[:], src[head+BlockSize:n])
+	if head == 0 {
+		copy(prev[:], iv)
+	} else {
+		copy(prev[:], src[head-BlockSize:head])
+	}
+
+	// D(C(n)) = C(n-1) xor (P(n) || zeros), so its last BlockSize-d bytes
+	// complete the stolen C(n-1) and its first d bytes give P(n).
+	var z [BlockSize]byte
+	b.Decrypt(z[:], cn[:])
+	copy(cn1[d:], z[d:])
+	subtle.XORBytes(z[:d], z[:d], cn1[:d])
+
+	var pn1 [BlockSize]byte
+	b.Decrypt(pn1[:], cn1[:])
+	subtle.XORBytes(pn1[:], pn1[:], prev[:])
+
+	cipher.NewCBCDecrypter(b, iv).CryptBlocks(dst[:head], src[:head])
+	copy(dst[head:], pn1[:])
+	copy(dst[head+BlockSize:n], z[:d])
+	return nil
+}
@@ -0,0 +1,98 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+// TestCTSAES checks the CS3 construction against the AES CBC-CTS vectors
+// of RFC 3962, appendix B; there are no published SM4 vectors.
+func TestCTSAES(t *testing.T) {
+	b, _ := aes.NewCipher(decodeHex("636869636b656e207465726979616b69"))
+	iv := make([]byte, BlockSize)
+	for _, c := range []struct{ in, out string }{
+		{
+			"4920776f756c64206c696b652074686520",
+			"c6353568f2bf8cb4d8a580362da7ff7f97",
+		},
+		{
+			"4920776f756c64206c696b65207468652047656e6572616c20476175277320",
+			"fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5",
+		},
+		{
+			"4920776f756c64206c696b65207468652047656e6572616c2047617527732043",
+			"39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584",
+		},
+	} {
+		in, want := decodeHex(c.in), decodeHex(c.out)
+		got := make([]byte, len(in))
+		if err := ctsEncrypt(b, iv, got, in); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%d bytes: got %x, want %x", len(in), got, want)
+		}
+		if err := ctsDecrypt(b, iv, got, got); err != nil || !bytes.Equal(got, in) {
+			t.Errorf("%d bytes: decryption = %x, %v", len(in), got, err)
+		}
+	}
+}
+
+func TestCBCCTS(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	iv := []byte("fedcba0987654321")
+	c, err := NewCBCCTS(key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewCipher(key)
+	for _, n := range []int{16, 17, 31, 32, 33, 48, 100} {
+		msg := make([]byte, n)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+		ct := make([]byte, n)
+		if err := c.Encrypt(ct, msg); err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if bytes.Equal(ct, msg) {
+			t.Errorf("%d bytes: ciphertext equals plaintext", n)
+		}
+		// Everything before the last two blocks is plain CBC.
+		head, _ := ctsSplit(n)
+		if n == BlockSize {
+			head = BlockSize
+		}
+		cbc := make([]byte, head)
+		cipher.NewCBCEncrypter(b, iv).CryptBlocks(cbc, msg[:head])
+		if !bytes.Equal(ct[:head], cbc) {
+			t.Errorf("%d bytes: leading blocks differ from CBC", n)
+		}
+
+		got := make([]byte, n)
+		if err := c.Decrypt(got, ct); err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("%d bytes: Decrypt = %x, %v", n, got, err)
+		}
+		inPlace := append([]byte{}, msg...)
+		c.Encrypt(inPlace, inPlace)
+		if !bytes.Equal(inPlace, ct) {
+			t.Errorf("%d bytes: in-place encryption differs", n)
+		}
+		c.Decrypt(inPlace, inPlace)
+		if !bytes.Equal(inPlace, msg) {
+			t.Errorf("%d bytes: in-place decryption differs", n)
+		}
+	}
+
+	if err := c.Encrypt(make([]byte, 15), make([]byte, 15)); err == nil {
+		t.Error("Encrypt accepted 15 bytes")
+	}
+	if err := c.Decrypt(make([]byte, 16), make([]byte, 17)); err == nil {
+		t.Error("Decrypt accepted a short dst")
+	}
+	if _, err := NewCBCCTS(key, iv[:8]); err == nil {
+		t.Error("NewCBCCTS accepted an 8 byte IV")
+	}
+}
@@ -0,0 +1,119 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+
+	"crypto/sm/sm3"
+)
+
+const cbcHMACTagSize = sm3.Size
+
+// cbcHMAC is encrypt-then-MAC with SM4-CBC and HMAC-SM3. The nonce is the
+// CBC IV. The tag is HMAC-SM3 over
+//
+//	additionalData || IV || ciphertext || uint64(8·len(additionalData))
+//
+// with the length big-endian, following the composition of RFC 7518,
+// section 5.2.2, but without truncating the tag.
+type cbcHMAC struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+// NewCBCHMAC returns an AEAD that encrypts with SM4-CBC under encKey, using
+// PKCS #7 padding, and then authenticates the IV, ciphertext and additional
+// data with HMAC-SM3 under macKey. The nonce is the 16 byte IV, which must
+// be unpredictable, e.g. random, for every message. macKey must be at least
+// 16 bytes and independent of encKey. The ciphertext is the padded CBC
+// output followed by a 32 byte tag.
+func NewCBCHMAC(encKey, macKey []byte) (cipher.AEAD, error) {
+	if len(macKey) < 16 {
+		return nil, errors.New("sm4: HMAC key must be at least 16 bytes")
+	}
+	b, err := NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &cbcHMAC{block: b, macKey: append([]byte{}, macKey...)}, nil
+}
+
+// CBCAEADKeySize is the size of the key taken by Sm4CbcAEAD.
+const CBCAEADKeySize = 32
+
+// Sm4CbcAEAD returns the recommended way to use SM4-CBC: the
+// encrypt-then-MAC AEAD of NewCBCHMAC, with both keys taken from a single
+// CBCAEADKeySize byte key. As in RFC 7518, section 5.2.2.1, the first half
+// of key is the HMAC-SM3 key and the second half the SM4 key. Raw CBC, as
+// with cipher.NewCBCEncrypter, has no integrity protection and exposes
+// its PKCS #7 padding check to padding oracle attacks, so it should only
+// be used where the ciphertext is authenticated some other way.
+func Sm4CbcAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != CBCAEADKeySize {
+		return nil, errors.New("sm4: CBC AEAD key must be 32 bytes")
+	}
+	return NewCBCHMAC(key[16:], key[:16])
+}
+
+func (c *cbcHMAC) NonceSize() int { return BlockSize }
+
+// Overhead returns the maximum difference between the lengths of a
+// ciphertext and its plaintext: a full block of padding plus the tag.
+func (c *cbcHMAC) Overhead() int { return BlockSize + cbcHMACTagSize }
+
+func (c *cbcHMAC) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != BlockSize {
+		panic("sm4: incorrect nonce length given to CBC-HMAC")
+	}
+	padding := BlockSize - len(plaintext)%BlockSize
+	ctLen := len(plaintext) + padding
+	ret, out := sliceForAppend(dst, ctLen+cbcHMACTagSize)
+	copy(out, plaintext)
+	for i := len(plaintext); i < ctLen; i++ {
+		out[i] = byte(padding)
+	}
+	cipher.NewCBCEncrypter(c.block, nonce).CryptBlocks(out[:ctLen], out[:ctLen])
+	c.tag(out[ctLen:ctLen], nonce, out[:ctLen], additionalData)
+	return ret
+}
+
+func (c *cbcHMAC) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != BlockSize {
+		panic("sm4: incorrect nonce length given to CBC-HMAC")
+	}
+	ctLen := len(ciphertext) - cbcHMACTagSize
+	if ctLen < BlockSize || ctLen%BlockSize != 0 {
+		return nil, errOpen
+	}
+	ct, tag := ciphertext[:ctLen], ciphertext[ctLen:]
+	expected := c.tag(make([]byte, 0, cbcHMACTagSize), nonce, ct, additionalData)
+	if !hmac.Equal(expected, tag) {
+		return nil, errOpen
+	}
+
+	pt := make([]byte, ctLen)
+	cipher.NewCBCDecrypter(c.block, nonce).CryptBlocks(pt, ct)
+	pt, err := pkcs7UnPadding(pt)
+	if err != nil {
+		// Unreachable for ciphertexts produced by Seal; the tag already
+		// authenticated the padding.
+		return nil, errOpen
+	}
+	ret, out := sliceForAppend(dst, len(pt))
+	copy(out, pt)
+	return ret, nil
+}
+
+// tag appends the authentication tag to dst.
+func (c *cbcHMAC) tag(dst, iv, ciphertext, additionalData []byte) []byte {
+	mac := hmac.New(sm3.New, c.macKey)
+	mac.Write(additionalData)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	var al [8]byte
+	binary.BigEndian.PutUint64(al[:], uint64(len(additionalData))*8)
+	mac.Write(al[:])
+	return mac.Sum(dst)
+}
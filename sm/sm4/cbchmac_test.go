@@ -0,0 +1,91 @@
+package sm4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCBCHMAC(t *testing.T) {
+	encKey := []byte("1234567890abcdef")
+	macKey := []byte("a separate mac key")
+	aead, err := NewCBCHMAC(encKey, macKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := []byte("fedcba0987654321")
+	aad := []byte("record header")
+	for _, n := range []int{0, 1, 15, 16, 17, 100} {
+		msg := bytes.Repeat([]byte{'m'}, n)
+		sealed := aead.Seal(nil, iv, msg, aad)
+		if max := n + aead.Overhead(); len(sealed) > max {
+			t.Errorf("%d byte message sealed to %d bytes, more than %d", n, len(sealed), max)
+		}
+		got, err := aead.Open(nil, iv, sealed, aad)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("Open = %q, want %q", got, msg)
+		}
+
+		for i := range sealed {
+			bad := append([]byte{}, sealed...)
+			bad[i] ^= 1
+			if _, err := aead.Open(nil, iv, bad, aad); err == nil {
+				t.Fatalf("%d bytes: Open accepted a change at byte %d of %d", n, i, len(sealed))
+			}
+		}
+		if _, err := aead.Open(nil, iv, sealed, []byte("other header")); err == nil {
+			t.Error("Open accepted different additional data")
+		}
+		otherIV := append([]byte{}, iv...)
+		otherIV[0] ^= 1
+		if _, err := aead.Open(nil, otherIV, sealed, aad); err == nil {
+			t.Error("Open accepted a different IV")
+		}
+		if _, err := aead.Open(nil, iv, sealed[:len(sealed)-1], aad); err == nil {
+			t.Error("Open accepted a truncated message")
+		}
+	}
+
+	other, _ := NewCBCHMAC(encKey, []byte("yet another mac key"))
+	if _, err := other.Open(nil, iv, aead.Seal(nil, iv, []byte("x"), nil), nil); err == nil {
+		t.Error("Open succeeded with the wrong MAC key")
+	}
+	if _, err := NewCBCHMAC(encKey, macKey[:15]); err == nil {
+		t.Error("NewCBCHMAC accepted a 15 byte MAC key")
+	}
+}
+
+func TestSm4CbcAEAD(t *testing.T) {
+	key := []byte("mac key 16 bytesenc key 16 bytes")
+	aead, err := Sm4CbcAEAD(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, _ := NewCBCHMAC(key[16:], key[:16])
+	iv := []byte("fedcba0987654321")
+	msg := []byte("attack at dawn, bring padding")
+	sealed := aead.Seal(nil, iv, msg, nil)
+	if want := split.Seal(nil, iv, msg, nil); !bytes.Equal(sealed, want) {
+		t.Errorf("Seal = %x, want %x as from NewCBCHMAC", sealed, want)
+	}
+
+	// Flipping the last byte of the second to last ciphertext block flips
+	// the final padding byte on decryption: the classic padding oracle
+	// probe. It must be rejected before any padding is looked at.
+	last := len(sealed) - cbcHMACTagSize - BlockSize - 1
+	for _, i := range []int{0, last, len(sealed) - 1} {
+		bad := append([]byte{}, sealed...)
+		bad[i] ^= 1
+		if _, err := aead.Open(nil, iv, bad, nil); err != errOpen {
+			t.Errorf("Open with byte %d changed: err = %v, want %v", i, err, errOpen)
+		}
+	}
+
+	for _, n := range []int{0, 16, 31, 33} {
+		if _, err := Sm4CbcAEAD(make([]byte, n)); err == nil {
+			t.Errorf("Sm4CbcAEAD accepted a %d byte key", n)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"strconv"
+)
+
+type KeySizeError int
+
+func (k KeySizeError) Error() string {
+	return "sm4: invalid key size " + strconv.Itoa(int(k))
+}
+
+// sm4Cipher is an instance of SM4 encryption using a particular key.
+type sm4Cipher struct {
+	enc [32]uint32
+	dec [32]uint32
+}
+
+// NewCipher creates and returns a new cipher.Block.
+// The key argument must be 16 bytes long.
+func NewCipher(key []byte) (cipher.Block, error) {
+	c := new(sm4Cipher)
+	if err := c.Rekey(key); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Rekey replaces the round keys of c with those derived from key, without
+// allocating a new cipher. Blocks returned by NewCipher can be rekeyed via
+// an interface{ Rekey([]byte) error } assertion.
+func (c *sm4Cipher) Rekey(key []byte) error {
+	if len(key) != BlockSize {
+		return KeySizeError(len(key))
+	}
+	c.enc = keyExp(keyToUint32(key))
+	c.dec = rk_swap(c.enc)
+	return nil
+}
+
+func (c *sm4Cipher) BlockSize() int { return BlockSize }
+
+func (c *sm4Cipher) Encrypt(dst, src []byte) {
+	if len(src) < BlockSize {
+		panic("sm4: input not full block")
+	}
+	if len(dst) < BlockSize {
+		panic("sm4: output not full block")
+	}
+	cryptBlock(&c.enc, dst, src)
+}
+
+func (c *sm4Cipher) Decrypt(dst, src []byte) {
+	if len(src) < BlockSize {
+		panic("sm4: input not full block")
+	}
+	if len(dst) < BlockSize {
+		panic("sm4: output not full block")
+	}
+	cryptBlock(&c.dec, dst, src)
+}
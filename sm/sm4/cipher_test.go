@@ -0,0 +1,73 @@
+package sm4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewCipher(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	want, _ := hex.DecodeString("681edf34d206965e86b3e94f536e4246")
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]byte, BlockSize)
+	c.Encrypt(dst, key)
+	if !bytes.Equal(dst, want) {
+		t.Fatalf("Encrypt = %x, want %x", dst, want)
+	}
+	c.Decrypt(dst, dst)
+	if !bytes.Equal(dst, key) {
+		t.Fatalf("Decrypt = %x, want %x", dst, key)
+	}
+	if !bytes.Equal(Sm4Ecb(key, key, ENC)[:BlockSize], want) {
+		t.Fatal("Sm4Ecb and NewCipher disagree")
+	}
+
+	if _, err := NewCipher(key[:15]); err != KeySizeError(15) {
+		t.Errorf("NewCipher with 15-byte key: err = %v, want KeySizeError(15)", err)
+	}
+}
+
+func TestRekey(t *testing.T) {
+	oldKey := []byte("1234567890abcdef")
+	newKey := []byte("fedcba0987654321")
+	msg := []byte("sixteen byte msg")
+
+	c, err := NewCipher(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldOut := make([]byte, BlockSize)
+	c.Encrypt(oldOut, msg)
+
+	r, ok := c.(interface{ Rekey([]byte) error })
+	if !ok {
+		t.Fatal("cipher does not implement Rekey")
+	}
+	if err := r.Rekey(newKey); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _ := NewCipher(newKey)
+	want := make([]byte, BlockSize)
+	fresh.Encrypt(want, msg)
+
+	got := make([]byte, BlockSize)
+	c.Encrypt(got, msg)
+	if !bytes.Equal(got, want) {
+		t.Errorf("after Rekey Encrypt = %x, want %x", got, want)
+	}
+	if bytes.Equal(got, oldOut) {
+		t.Error("after Rekey cipher still encrypts with the old key")
+	}
+	c.Decrypt(got, got)
+	if !bytes.Equal(got, msg) {
+		t.Errorf("after Rekey Decrypt = %x, want %x", got, msg)
+	}
+
+	if err := r.Rekey(newKey[:8]); err == nil {
+		t.Error("Rekey accepted a short key")
+	}
+}
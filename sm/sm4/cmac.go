@@ -0,0 +1,49 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+// cmacSubkeys derives the CMAC subkeys K1 and K2 of NIST SP 800-38B from b.
+func cmacSubkeys(b cipher.Block) (k1, k2 [BlockSize]byte) {
+	var l [BlockSize]byte
+	b.Encrypt(l[:], l[:])
+	k1 = cmacDouble(l)
+	k2 = cmacDouble(k1)
+	return
+}
+
+// cmacDouble multiplies x by the generator of GF(2¹²⁸) in the CMAC
+// representation: a left shift, reduced by 0x87.
+func cmacDouble(x [BlockSize]byte) [BlockSize]byte {
+	var y [BlockSize]byte
+	msb := x[0] >> 7
+	for i := 0; i < BlockSize-1; i++ {
+		y[i] = x[i]<<1 | x[i+1]>>7
+	}
+	y[BlockSize-1] = x[BlockSize-1]<<1 ^ -msb&0x87
+	return y
+}
+
+// cmacSum returns the CMAC (NIST SP 800-38B) of msg under b.
+func cmacSum(b cipher.Block, msg []byte) [BlockSize]byte {
+	k1, k2 := cmacSubkeys(b)
+	var x [BlockSize]byte
+	for len(msg) > BlockSize {
+		subtle.XORBytes(x[:], x[:], msg[:BlockSize])
+		b.Encrypt(x[:], x[:])
+		msg = msg[BlockSize:]
+	}
+	var last [BlockSize]byte
+	copy(last[:], msg)
+	if len(msg) == BlockSize {
+		subtle.XORBytes(last[:], last[:], k1[:])
+	} else {
+		last[len(msg)] = 0x80
+		subtle.XORBytes(last[:], last[:], k2[:])
+	}
+	subtle.XORBytes(x[:], x[:], last[:])
+	b.Encrypt(x[:], x[:])
+	return x
+}
@@ -0,0 +1,157 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// CTRNonceSize is the length of the fixed nonce prefix of the counter block
+// used by NewCTRWithNonce. The remaining 4 bytes hold the counter.
+const CTRNonceSize = 12
+
+// ctr is SM4 in counter mode. The last width bytes of the counter block
+// form a big-endian counter that is incremented once per block; the bytes
+// before it are fixed.
+type ctr struct {
+	b      cipher.Block
+	width  int             // length of the counter field, 4 or BlockSize
+	limit  uint64          // maximum number of blocks, 0 if unbounded
+	block  [BlockSize]byte // counter block of the current keystream block
+	blocks uint64          // keystream blocks consumed so far
+	ks     [BlockSize]byte
+	have   bool // ks holds the encryption of block
+	used   int  // bytes of the current keystream block consumed
+	// wrapped is set when a full-width counter has passed 2^128 - 1, after
+	// which the stream refuses to produce keystream.
+	wrapped bool
+}
+
+// NewCTR returns an SM4 CTR stream starting at the counter block iv, which
+// is incremented as a 128-bit big-endian integer, producing the same
+// keystream as cipher.NewCTR. Where cipher.NewCTR would wrap the counter
+// from 2^128 - 1 to zero and go on, into counter blocks that another
+// stream under the same key may already have used, XORKeyStream panics
+// instead. The returned stream implements encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler; see MarshalBinary.
+func NewCTR(key, iv []byte) (cipher.Stream, error) {
+	if len(iv) != BlockSize {
+		return nil, errors.New("sm4: IV length must equal block size")
+	}
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	s := &ctr{b: c, width: BlockSize}
+	copy(s.block[:], iv)
+	return s, nil
+}
+
+// NewCTRWithNonce returns an SM4 CTR stream whose i-th keystream block is
+// the encryption of nonce || uint32(startCounter + i), with the counter
+// encoded big-endian. The counter wraps modulo 2^32 without carrying into
+// the nonce; XORKeyStream panics rather than produce more than 2^32 blocks,
+// after which the keystream would repeat. The nonce must be CTRNonceSize
+// bytes long. The returned stream is marshalable like that of NewCTR.
+func NewCTRWithNonce(key, nonce []byte, startCounter uint32) (cipher.Stream, error) {
+	if len(nonce) != CTRNonceSize {
+		return nil, errors.New("sm4: CTR nonce must be 12 bytes")
+	}
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	s := &ctr{b: c, width: BlockSize - CTRNonceSize, limit: 1 << 32}
+	copy(s.block[:], nonce)
+	binary.BigEndian.PutUint32(s.block[CTRNonceSize:], startCounter)
+	return s, nil
+}
+
+func (s *ctr) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("sm4: output smaller than input")
+	}
+	for len(src) > 0 {
+		if !s.have {
+			if s.wrapped || s.limit != 0 && s.blocks == s.limit {
+				panic("sm4: CTR counter exhausted")
+			}
+			s.b.Encrypt(s.ks[:], s.block[:])
+			s.have = true
+		}
+		n := len(src)
+		if n > BlockSize-s.used {
+			n = BlockSize - s.used
+		}
+		for i := 0; i < n; i++ {
+			dst[i] = src[i] ^ s.ks[s.used+i]
+		}
+		s.used += n
+		dst, src = dst[n:], src[n:]
+		if s.used == BlockSize {
+			s.advance()
+		}
+	}
+}
+
+// advance moves to the next counter block.
+func (s *ctr) advance() {
+	carry := true
+	for i := BlockSize - 1; i >= BlockSize-s.width; i-- {
+		s.block[i]++
+		if s.block[i] != 0 {
+			carry = false
+			break
+		}
+	}
+	if carry && s.width == BlockSize {
+		s.wrapped = true
+	}
+	s.blocks++
+	s.have = false
+	s.used = 0
+}
+
+const ctrStateVersion = 1
+
+// ctrStateLen is the length of a marshaled CTR state: version, counter
+// width, offset within the current block, blocks consumed and the counter
+// block.
+const ctrStateLen = 3 + 8 + BlockSize
+
+var errCTRState = errors.New("sm4: invalid CTR state")
+
+// MarshalBinary returns the position of the stream: the current counter
+// block and the offset into its keystream. The key is not included, and
+// the state reveals no keystream, so it may be stored wherever the IV
+// could be. To resume, create a stream with the same key and constructor
+// and call UnmarshalBinary on it. An exhausted stream cannot be marshaled.
+func (s *ctr) MarshalBinary() ([]byte, error) {
+	if s.wrapped {
+		return nil, errors.New("sm4: CTR counter exhausted")
+	}
+	out := make([]byte, ctrStateLen)
+	out[0], out[1], out[2] = ctrStateVersion, byte(s.width), byte(s.used)
+	binary.BigEndian.PutUint64(out[3:], s.blocks)
+	copy(out[11:], s.block[:])
+	return out, nil
+}
+
+// UnmarshalBinary restores a position saved by MarshalBinary. The state
+// must come from a stream of the same kind, NewCTR or NewCTRWithNonce.
+func (s *ctr) UnmarshalBinary(data []byte) error {
+	if len(data) != ctrStateLen || data[0] != ctrStateVersion ||
+		int(data[1]) != s.width || int(data[2]) >= BlockSize {
+		return errCTRState
+	}
+	blocks := binary.BigEndian.Uint64(data[3:])
+	if s.limit != 0 && blocks > s.limit {
+		return errCTRState
+	}
+	s.used = int(data[2])
+	s.blocks = blocks
+	copy(s.block[:], data[11:])
+	s.have = false
+	s.wrapped = false
+	return nil
+}
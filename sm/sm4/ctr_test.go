@@ -0,0 +1,159 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding"
+	"encoding/binary"
+	"testing"
+)
+
+func TestCTRWithNonce(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	nonce := []byte("fixed-nonce!")
+	const start = 0xfffffffe
+	c, _ := NewCipher(key)
+
+	// Build the expected keystream by hand; the counter wraps within its
+	// 32 bits and never carries into the nonce.
+	want := make([]byte, 0, 4*BlockSize)
+	for i := uint32(0); i < 4; i++ {
+		block := make([]byte, BlockSize)
+		copy(block, nonce)
+		binary.BigEndian.PutUint32(block[CTRNonceSize:], start+i)
+		c.Encrypt(block, block)
+		want = append(want, block...)
+	}
+
+	s, err := NewCTRWithNonce(key, nonce, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(want))
+	// Odd sized writes exercise partial block handling.
+	for off, n := 0, 1; off < len(got); off, n = off+n, n+3 {
+		if off+n > len(got) {
+			n = len(got) - off
+		}
+		s.XORKeyStream(got[off:off+n], got[off:off+n])
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("keystream = %x, want %x", got, want)
+	}
+
+	// With startCounter 0 the block layout matches standard CTR with a
+	// zero low word.
+	iv := append(append([]byte{}, nonce...), 0, 0, 0, 0)
+	ref := make([]byte, 3*BlockSize)
+	cipher.NewCTR(c, iv).XORKeyStream(ref, ref)
+	s, _ = NewCTRWithNonce(key, nonce, 0)
+	got = make([]byte, len(ref))
+	s.XORKeyStream(got, got)
+	if !bytes.Equal(got, ref) {
+		t.Errorf("keystream from counter 0 = %x, want %x", got, ref)
+	}
+
+	if _, err := NewCTRWithNonce(key, nonce[:8], 0); err == nil {
+		t.Error("NewCTRWithNonce accepted an 8-byte nonce")
+	}
+}
+
+func TestCTRWithNonceExhausted(t *testing.T) {
+	s, _ := NewCTRWithNonce([]byte("1234567890abcdef"), make([]byte, CTRNonceSize), 7)
+	// Pretend all but the last block of the counter space has been used.
+	s.(*ctr).blocks = 1<<32 - 1
+	buf := make([]byte, BlockSize)
+	s.XORKeyStream(buf, buf)
+	defer func() {
+		if recover() == nil {
+			t.Error("XORKeyStream did not panic after 2^32 blocks")
+		}
+	}()
+	s.XORKeyStream(buf[:1], buf[:1])
+}
+
+func TestNewCTR(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	iv := decodeHex("00000000000000000000fffffffffffe")
+	c, _ := NewCipher(key)
+	want := make([]byte, 5*BlockSize)
+	cipher.NewCTR(c, iv).XORKeyStream(want, want)
+
+	s, err := NewCTR(key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(want))
+	s.XORKeyStream(got, got)
+	if !bytes.Equal(got, want) {
+		t.Errorf("NewCTR keystream = %x, want %x", got, want)
+	}
+	if _, err := NewCTR(key, iv[:12]); err == nil {
+		t.Error("NewCTR accepted a 12 byte IV")
+	}
+}
+
+func TestCTRWrapGuard(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	iv := decodeHex("fffffffffffffffffffffffffffffffd")
+	c, _ := NewCipher(key)
+	want := make([]byte, 3*BlockSize)
+	cipher.NewCTR(c, iv).XORKeyStream(want, want)
+
+	// The counter blocks ...fd, ...fe and ...ff are usable; the next would
+	// wrap to zero.
+	s, _ := NewCTR(key, iv)
+	got := make([]byte, len(want))
+	s.XORKeyStream(got, got)
+	if !bytes.Equal(got, want) {
+		t.Errorf("keystream before wrap = %x, want %x", got, want)
+	}
+	if _, err := s.(encoding.BinaryMarshaler).MarshalBinary(); err == nil {
+		t.Error("MarshalBinary succeeded on an exhausted stream")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("XORKeyStream did not panic when the counter wrapped")
+		}
+	}()
+	s.XORKeyStream(got[:1], got[:1])
+}
+
+func TestCTRMarshalBinary(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	iv := decodeHex("000102030405060708090a0b0c0d0e0f")
+	msg := bytes.Repeat([]byte("checkpointed stream "), 20)
+	want := make([]byte, len(msg))
+	s, _ := NewCTR(key, iv)
+	s.XORKeyStream(want, msg)
+
+	for _, cut := range []int{0, 1, 15, 16, 17, 100, len(msg)} {
+		s, _ := NewCTR(key, iv)
+		got := make([]byte, len(msg))
+		s.XORKeyStream(got[:cut], msg[:cut])
+		state, err := s.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Resume in a fresh stream, as after a restart.
+		resumed, _ := NewCTR(key, make([]byte, BlockSize))
+		if err := resumed.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+			t.Fatal(err)
+		}
+		resumed.XORKeyStream(got[cut:], msg[cut:])
+		if !bytes.Equal(got, want) {
+			t.Errorf("stream resumed at %d differs", cut)
+		}
+	}
+
+	s, _ = NewCTR(key, iv)
+	state, _ := s.(encoding.BinaryMarshaler).MarshalBinary()
+	other, _ := NewCTRWithNonce(key, make([]byte, CTRNonceSize), 0)
+	if err := other.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err == nil {
+		t.Error("NewCTRWithNonce stream accepted NewCTR state")
+	}
+	if err := s.(encoding.BinaryUnmarshaler).UnmarshalBinary(state[:10]); err == nil {
+		t.Error("UnmarshalBinary accepted a truncated state")
+	}
+}
@@ -0,0 +1,74 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+// EIASize is the length in bytes of the MAC-I produced by Sm4EIA.
+const EIASize = 4
+
+var errEEAParams = errors.New("sm4: bearer must be in [0, 31] and direction 0 or 1")
+
+// Sm4EEA encrypts or decrypts data with the 128-EEA2 construction of 3GPP
+// TS 33.401, annex B.1.3, with SM4 in place of AES: CTR mode starting from
+// the counter block COUNT || BEARER || DIRECTION || 0^26 || 0^64. Applying
+// it twice with the same inputs returns the original data.
+//
+// bearer is the 5-bit radio bearer identity and direction is 0 for uplink
+// and 1 for downlink. data is a whole number of bytes.
+func Sm4EEA(key []byte, count uint32, bearer, direction int, data []byte) ([]byte, error) {
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return eea(b, count, bearer, direction, data)
+}
+
+// Sm4EIA computes the 32-bit MAC-I of data with the 128-EIA2 construction
+// of 3GPP TS 33.401, annex B.2.3, with SM4 in place of AES: the leftmost 32
+// bits of the CMAC of COUNT || BEARER || DIRECTION || 0^26 || data. The
+// inputs are as for Sm4EEA.
+func Sm4EIA(key []byte, count uint32, bearer, direction int, data []byte) ([]byte, error) {
+	b, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return eia(b, count, bearer, direction, data)
+}
+
+// eeaHeader returns the 64-bit COUNT || BEARER || DIRECTION || 0^26 prefix
+// shared by EEA2 and EIA2.
+func eeaHeader(count uint32, bearer, direction int) ([8]byte, error) {
+	var h [8]byte
+	if bearer < 0 || bearer > 31 || direction < 0 || direction > 1 {
+		return h, errEEAParams
+	}
+	h[0], h[1], h[2], h[3] = byte(count>>24), byte(count>>16), byte(count>>8), byte(count)
+	h[4] = byte(bearer)<<3 | byte(direction)<<2
+	return h, nil
+}
+
+func eea(b cipher.Block, count uint32, bearer, direction int, data []byte) ([]byte, error) {
+	h, err := eeaHeader(count, bearer, direction)
+	if err != nil {
+		return nil, err
+	}
+	var iv [BlockSize]byte
+	copy(iv[:], h[:])
+	out := make([]byte, len(data))
+	cipher.NewCTR(b, iv[:]).XORKeyStream(out, data)
+	return out, nil
+}
+
+func eia(b cipher.Block, count uint32, bearer, direction int, data []byte) ([]byte, error) {
+	h, err := eeaHeader(count, bearer, direction)
+	if err != nil {
+		return nil, err
+	}
+	m := make([]byte, 0, len(h)+len(data))
+	m = append(m, h[:]...)
+	m = append(m, data...)
+	t := cmacSum(b, m)
+	return t[:EIASize], nil
+}
@@ -0,0 +1,151 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+// RFC 4493, section 4: AES-128 CMAC examples.
+func TestCMAC(t *testing.T) {
+	b, _ := aes.NewCipher(decodeHex("2b7e151628aed2a6abf7158809cf4f3c"))
+	msg := decodeHex("6bc1bee22e409f96e93d7e117393172a" +
+		"ae2d8a571e03ac9c9eb76fac45af8e51" +
+		"30c81c46a35ce411e5fbc1191a0a52ef" +
+		"f69f2445df4f9b17ad2b417be66c3710")
+	for _, c := range []struct {
+		n    int
+		want string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	} {
+		if got := cmacSum(b, msg[:c.n]); !bytes.Equal(got[:], decodeHex(c.want)) {
+			t.Errorf("CMAC of %d bytes = %x, want %s", c.n, got, c.want)
+		}
+	}
+}
+
+// 3GPP TS 33.401, annex C.1 and C.2: 128-EEA2 and 128-EIA2 test sets,
+// which are the EEA and EIA constructions with AES. Only the test sets of a
+// whole number of bytes are used for EIA; for EEA the bits past length in
+// the last byte are cleared before comparing, as in the specification.
+func TestEEA2EIA2(t *testing.T) {
+	for i, c := range []struct {
+		key               string
+		count             uint32
+		bearer, direction int
+		bits              int
+		plaintext         string
+		ciphertext        string
+	}{
+		{
+			"d3c5d592327fb11c4035c6680af8c6d1", 0x398a59b4, 0x15, 1, 253,
+			"981ba6824c1bfb1ab485472029b71d808ce33e2cc3c0b5fc1f3de8a6dc66b1f0",
+			"e9fed8a63d155304d71df20bf3e82214b20ed7dad2f233dc3c22d7bdeeed8e78",
+		},
+		{
+			"2bd6459f82c440e0952c49104805ff48", 0xc675a64b, 0x0c, 1, 798,
+			"7ec61272743bf1614726446a6c38ced166f6ca76eb5430044286346cef130f92" +
+				"922b03450d3a9975e5bd2ea0eb55ad8e1b199e3ec4316020e9a1b285e7627953" +
+				"59b7bdfd39bef4b2484583d5afe082aee638bf5fd5a606193901a08f4ab41aab" +
+				"9b134880",
+			"5961605353c64bdca15b195e288553a910632506d6200aa790c4c806c99904cf" +
+				"2445cc50bb1cf168a49673734e081b57e324ce5259c0e78d4cd97b870976503c" +
+				"0943f2cb5ae8f052c7b7d392239587b8956086bcab18836042e2e6ce42432a17" +
+				"105c53d0",
+		},
+	} {
+		b, _ := aes.NewCipher(decodeHex(c.key))
+		got, err := eea(b, c.count, c.bearer, c.direction, decodeHex(c.plaintext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := c.bits % 8; r != 0 {
+			got[len(got)-1] &= byte(0xff << (8 - r))
+		}
+		if want := decodeHex(c.ciphertext); !bytes.Equal(got, want) {
+			t.Errorf("EEA2 test set %d = %x, want %x", i+1, got, want)
+		}
+	}
+
+	b, _ := aes.NewCipher(decodeHex("d3c5d592327fb11c4035c6680af8c6d1"))
+	mac, err := eia(b, 0x398a59b4, 0x1a, 1, decodeHex("484583d5afe082ae"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := decodeHex("b93787e6"); !bytes.Equal(mac, want) {
+		t.Errorf("EIA2 test set 1 = %x, want %x", mac, want)
+	}
+}
+
+func TestSm4EEA(t *testing.T) {
+	key := decodeHex("0123456789abcdeffedcba9876543210")
+	msg := []byte("a PDCP payload that spans more than one block")
+	ct, err := Sm4EEA(key, 0x12345678, 7, 1, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ct, msg) {
+		t.Fatal("Sm4EEA did not change the data")
+	}
+	pt, err := Sm4EEA(key, 0x12345678, 7, 1, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pt, msg) {
+		t.Errorf("Sm4EEA round trip = %q, want %q", pt, msg)
+	}
+	other, _ := Sm4EEA(key, 0x12345678, 7, 0, msg)
+	if bytes.Equal(other, ct) {
+		t.Error("direction does not affect the keystream")
+	}
+}
+
+func TestSm4EIA(t *testing.T) {
+	key := decodeHex("0123456789abcdeffedcba9876543210")
+	msg := []byte("an RRC message")
+	mac, err := Sm4EIA(key, 0x12345678, 7, 1, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mac) != EIASize {
+		t.Fatalf("MAC-I is %d bytes, want %d", len(mac), EIASize)
+	}
+	b, _ := NewCipher(key)
+	full := cmacSum(b, append(decodeHex("123456783c000000"), msg...))
+	if !bytes.Equal(mac, full[:EIASize]) {
+		t.Errorf("Sm4EIA = %x, want %x", mac, full[:EIASize])
+	}
+	for _, c := range []struct {
+		count             uint32
+		bearer, direction int
+		msg               []byte
+	}{
+		{0x12345679, 7, 1, msg},
+		{0x12345678, 8, 1, msg},
+		{0x12345678, 7, 0, msg},
+		{0x12345678, 7, 1, []byte("an RRC messagf")},
+	} {
+		if other, _ := Sm4EIA(key, c.count, c.bearer, c.direction, c.msg); bytes.Equal(other, mac) {
+			t.Errorf("Sm4EIA(%x, %d, %d, %q) collides with the original", c.count, c.bearer, c.direction, c.msg)
+		}
+	}
+}
+
+func TestEEAParams(t *testing.T) {
+	key := make([]byte, 16)
+	for _, p := range [][2]int{{-1, 0}, {32, 0}, {0, 2}, {0, -1}} {
+		if _, err := Sm4EEA(key, 0, p[0], p[1], nil); err == nil {
+			t.Errorf("Sm4EEA accepted bearer %d, direction %d", p[0], p[1])
+		}
+		if _, err := Sm4EIA(key, 0, p[0], p[1], nil); err == nil {
+			t.Errorf("Sm4EIA accepted bearer %d, direction %d", p[0], p[1])
+		}
+	}
+	if _, err := Sm4EEA(key[:15], 0, 0, 0, nil); err == nil {
+		t.Error("Sm4EEA accepted a 15 byte key")
+	}
+}
@@ -0,0 +1,239 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	gcmStandardNonceSize = 12
+	gcmTagSize           = 16
+	gcmMinimumTagSize    = 12
+)
+
+// sm4GCM is SM4 in Galois Counter Mode (NIST SP 800-38D). GHASH is
+// computed in constant time by ghashMul, or through a precomputed 4-bit
+// table for NewGCMWithTable.
+type sm4GCM struct {
+	cipher    cipher.Block
+	nonceSize int
+	tagSize   int
+	ghash     ghashKey
+}
+
+// NewGCM returns SM4 in Galois Counter Mode with the standard 12 byte nonce
+// and 16 byte tag.
+func NewGCM(key []byte) (cipher.AEAD, error) {
+	return newGCM(key, gcmStandardNonceSize, gcmTagSize, false)
+}
+
+// NewGCMWithTable returns SM4-GCM like NewGCM, but computes GHASH through a
+// precomputed table of multiples of the authentication key H instead of
+// the constant-time multiplication NewGCM uses. On 64-bit platforms the
+// two run at about the same speed; the table can be faster where 64-bit
+// multiplication is slow, as on 32-bit targets.
+//
+// The table lookups are indexed by data and key dependent values, so their
+// timing can leak H, and with it the ability to forge messages, to an
+// attacker who can observe the cache of the same machine. Only use it
+// where no untrusted code shares the hardware.
+func NewGCMWithTable(key []byte) (cipher.AEAD, error) {
+	return newGCM(key, gcmStandardNonceSize, gcmTagSize, true)
+}
+
+// NewGCMWithNonceSize returns SM4-GCM accepting nonces of the given length
+// in bytes. Nonces other than 12 bytes are hashed into the initial counter
+// block with GHASH, as specified by NIST SP 800-38D, so they are only
+// compatible with implementations that do the same.
+func NewGCMWithNonceSize(key []byte, size int) (cipher.AEAD, error) {
+	if size <= 0 {
+		return nil, errors.New("sm4: GCM nonce size must be positive")
+	}
+	return newGCM(key, size, gcmTagSize, false)
+}
+
+// NewGCMWithTagSize returns SM4-GCM with a 12 byte nonce and tags of the
+// given length in bytes, which must be between 12 and 16.
+func NewGCMWithTagSize(key []byte, size int) (cipher.AEAD, error) {
+	if size < gcmMinimumTagSize || size > gcmTagSize {
+		return nil, errors.New("sm4: GCM tag size must be between 12 and 16 bytes")
+	}
+	return newGCM(key, gcmStandardNonceSize, size, false)
+}
+
+func newGCM(key []byte, nonceSize, tagSize int, table bool) (*sm4GCM, error) {
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	var h [BlockSize]byte
+	c.Encrypt(h[:], h[:])
+	g := &sm4GCM{
+		cipher:    c,
+		nonceSize: nonceSize,
+		tagSize:   tagSize,
+		ghash:     ghashKey{h: loadFieldElement(h[:])},
+	}
+	if table {
+		g.ghash.table = newGHASHTable(g.ghash.h)
+	}
+	return g, nil
+}
+
+func (g *sm4GCM) NonceSize() int { return g.nonceSize }
+
+func (g *sm4GCM) Overhead() int { return g.tagSize }
+
+// gcmMaxPlaintext is the longest plaintext GCM can encrypt with a 32-bit
+// block counter, NIST SP 800-38D section 5.2.1.1.
+const gcmMaxPlaintext = (1<<32 - 2) * BlockSize
+
+func (g *sm4GCM) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != g.nonceSize {
+		panic("sm4: incorrect nonce length given to GCM")
+	}
+	if uint64(len(plaintext)) > gcmMaxPlaintext {
+		panic("sm4: message too large for GCM")
+	}
+	ret, out := sliceForAppend(dst, len(plaintext)+g.tagSize)
+
+	var counter, tagMask [BlockSize]byte
+	g.deriveCounter(&counter, nonce)
+	g.cipher.Encrypt(tagMask[:], counter[:])
+	gcmInc32(&counter)
+
+	g.counterCrypt(out, plaintext, &counter)
+	var tag [gcmTagSize]byte
+	g.auth(tag[:], out[:len(plaintext)], additionalData, &tagMask)
+	copy(out[len(plaintext):], tag[:g.tagSize])
+	return ret
+}
+
+func (g *sm4GCM) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != g.nonceSize {
+		panic("sm4: incorrect nonce length given to GCM")
+	}
+	if len(ciphertext) < g.tagSize || uint64(len(ciphertext)-g.tagSize) > gcmMaxPlaintext {
+		return nil, errOpen
+	}
+	tag := ciphertext[len(ciphertext)-g.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-g.tagSize]
+
+	var counter, tagMask [BlockSize]byte
+	g.deriveCounter(&counter, nonce)
+	g.cipher.Encrypt(tagMask[:], counter[:])
+	gcmInc32(&counter)
+
+	var expected [gcmTagSize]byte
+	g.auth(expected[:], ciphertext, additionalData, &tagMask)
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if subtle.ConstantTimeCompare(expected[:g.tagSize], tag) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errOpen
+	}
+	g.counterCrypt(out, ciphertext, &counter)
+	return ret, nil
+}
+
+// deriveCounter computes the initial counter block J0 for nonce.
+func (g *sm4GCM) deriveCounter(counter *[BlockSize]byte, nonce []byte) {
+	if len(nonce) == gcmStandardNonceSize {
+		copy(counter[:], nonce)
+		counter[BlockSize-1] = 1
+		return
+	}
+	var y gcmFieldElement
+	g.ghash.update(&y, nonce)
+	y.lo ^= uint64(len(nonce)) * 8
+	y = g.ghash.mul(y)
+	y.store(counter[:])
+}
+
+// gcmInc32 increments the rightmost 32 bits of counter modulo 2^32.
+func gcmInc32(counter *[BlockSize]byte) {
+	c := counter[BlockSize-4:]
+	binary.BigEndian.PutUint32(c, binary.BigEndian.Uint32(c)+1)
+}
+
+// counterCrypt XORs in with the keystream starting at counter.
+func (g *sm4GCM) counterCrypt(out, in []byte, counter *[BlockSize]byte) {
+	var mask [BlockSize]byte
+	for len(in) >= BlockSize {
+		g.cipher.Encrypt(mask[:], counter[:])
+		gcmInc32(counter)
+		for i := range mask {
+			out[i] = in[i] ^ mask[i]
+		}
+		out, in = out[BlockSize:], in[BlockSize:]
+	}
+	if len(in) > 0 {
+		g.cipher.Encrypt(mask[:], counter[:])
+		gcmInc32(counter)
+		for i := range in {
+			out[i] = in[i] ^ mask[i]
+		}
+	}
+}
+
+// auth computes the full-length GCM tag of ciphertext and additionalData
+// into out.
+func (g *sm4GCM) auth(out, ciphertext, additionalData []byte, tagMask *[BlockSize]byte) {
+	var y gcmFieldElement
+	g.ghash.update(&y, additionalData)
+	g.finishAuth(out, y, uint64(len(additionalData)), ciphertext, tagMask)
+}
+
+// finishAuth completes a tag from the GHASH state y after adLen bytes of
+// additional data, the last partial block of which has been zero padded.
+func (g *sm4GCM) finishAuth(out []byte, y gcmFieldElement, adLen uint64, ciphertext []byte, tagMask *[BlockSize]byte) {
+	g.ghash.update(&y, ciphertext)
+	y.hi ^= adLen * 8
+	y.lo ^= uint64(len(ciphertext)) * 8
+	y = g.ghash.mul(y)
+	y.store(out)
+	for i := range tagMask {
+		out[i] ^= tagMask[i]
+	}
+}
+
+// SealWithHeader encrypts and authenticates plaintext with SM4-GCM and
+// returns header || ciphertext || tag. The header is authenticated as
+// additional data but left in the clear, so it can be read before opening.
+func SealWithHeader(key, nonce, header, plaintext []byte) ([]byte, error) {
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("sm4: incorrect nonce length given to GCM")
+	}
+	out := make([]byte, len(header), len(header)+len(plaintext)+aead.Overhead())
+	copy(out, header)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// OpenWithHeader opens a message produced by SealWithHeader whose header is
+// headerLen bytes long. It returns the header and the plaintext only if the
+// header and the ciphertext are both authentic.
+func OpenWithHeader(key, nonce []byte, headerLen int, sealed []byte) (header, plaintext []byte, err error) {
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, nil, errors.New("sm4: incorrect nonce length given to GCM")
+	}
+	if headerLen < 0 || len(sealed)-headerLen < aead.Overhead() {
+		return nil, nil, errors.New("sm4: sealed message too short")
+	}
+	header = sealed[:headerLen]
+	plaintext, err = aead.Open(nil, nonce, sealed[headerLen:], header)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, plaintext, nil
+}
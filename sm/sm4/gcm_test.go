@@ -0,0 +1,247 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestGCM(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	nonce := make([]byte, 12)
+	aead, err := NewGCM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("this is a test")
+	ct := aead.Seal(nil, nonce, msg, []byte("aad"))
+	got, err := aead.Open(nil, nonce, ct, []byte("aad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("Open = %q, want %q", got, msg)
+	}
+	ct[0] ^= 1
+	if _, err := aead.Open(nil, nonce, ct, []byte("aad")); err == nil {
+		t.Error("Open accepted a tampered ciphertext")
+	}
+}
+
+func TestSealWithHeader(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	nonce := []byte("unique nonce")
+	header := []byte("route=eu-west;v=1")
+	msg := []byte("payload for the routed service")
+
+	sealed, err := SealWithHeader(key, nonce, header, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(sealed, header) {
+		t.Fatal("sealed message does not start with the cleartext header")
+	}
+	h, p, err := OpenWithHeader(key, nonce, len(header), sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(h, header) || !bytes.Equal(p, msg) {
+		t.Errorf("OpenWithHeader = (%q, %q), want (%q, %q)", h, p, header, msg)
+	}
+
+	tampered := append([]byte{}, sealed...)
+	copy(tampered[6:], "us")
+	if !bytes.HasPrefix(tampered, []byte("route=us")) {
+		t.Fatal("tampered header is not readable")
+	}
+	if _, _, err := OpenWithHeader(key, nonce, len(header), tampered); err == nil {
+		t.Error("OpenWithHeader accepted a modified header")
+	}
+
+	tampered = append([]byte{}, sealed...)
+	tampered[len(header)] ^= 1
+	if _, _, err := OpenWithHeader(key, nonce, len(header), tampered); err == nil {
+		t.Error("OpenWithHeader accepted a modified ciphertext")
+	}
+	if _, _, err := OpenWithHeader(key, nonce, len(sealed), sealed); err == nil {
+		t.Error("OpenWithHeader accepted a header length past the tag")
+	}
+	if _, err := SealWithHeader(key, nonce[:8], header, msg); err == nil {
+		t.Error("SealWithHeader accepted a short nonce")
+	}
+}
+
+func TestGCMWithNonceSize(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	aead, err := NewGCMWithNonceSize(key, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aead.NonceSize() != 16 {
+		t.Fatalf("NonceSize = %d, want 16", aead.NonceSize())
+	}
+	nonce := []byte("0123456789abcdef")
+	msg := []byte("record from the device")
+	ct := aead.Seal(nil, nonce, msg, nil)
+	got, err := aead.Open(nil, nonce, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("Open = %q, want %q", got, msg)
+	}
+	ct[len(ct)-1] ^= 1
+	if _, err := aead.Open(nil, nonce, ct, nil); err == nil {
+		t.Error("Open accepted a tampered tag")
+	}
+
+	for _, size := range []int{0, -1} {
+		if _, err := NewGCMWithNonceSize(key, size); err == nil {
+			t.Errorf("NewGCMWithNonceSize accepted size %d", size)
+		}
+	}
+}
+
+func TestGCMWithTagSize(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	nonce := make([]byte, 12)
+	aead, err := NewGCMWithTagSize(key, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("this is a test")
+	ct := aead.Seal(nil, nonce, msg, nil)
+	if len(ct) != len(msg)+12 {
+		t.Errorf("ciphertext is %d bytes, want %d", len(ct), len(msg)+12)
+	}
+	if _, err := aead.Open(nil, nonce, ct, nil); err != nil {
+		t.Fatal(err)
+	}
+	ct[0] ^= 1
+	if _, err := aead.Open(nil, nonce, ct, nil); err == nil {
+		t.Error("Open accepted a tampered ciphertext")
+	}
+
+	for _, size := range []int{0, 11, 17} {
+		if _, err := NewGCMWithTagSize(key, size); err == nil {
+			t.Errorf("NewGCMWithTagSize accepted size %d", size)
+		}
+	}
+}
+
+// TestGCMMatchesStdlib checks the implementation, with and without the
+// GHASH table, against the generic GCM of crypto/cipher wrapped around the
+// same SM4 block.
+func TestGCMMatchesStdlib(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	block, _ := NewCipher(key)
+	type ctor struct {
+		ours func() (cipher.AEAD, error)
+		std  func() (cipher.AEAD, error)
+	}
+	ctors := []ctor{
+		{func() (cipher.AEAD, error) { return NewGCM(key) },
+			func() (cipher.AEAD, error) { return cipher.NewGCM(block) }},
+		{func() (cipher.AEAD, error) { return NewGCMWithTable(key) },
+			func() (cipher.AEAD, error) { return cipher.NewGCM(block) }},
+		{func() (cipher.AEAD, error) { return NewGCMWithNonceSize(key, 16) },
+			func() (cipher.AEAD, error) { return cipher.NewGCMWithNonceSize(block, 16) }},
+		{func() (cipher.AEAD, error) { return NewGCMWithNonceSize(key, 7) },
+			func() (cipher.AEAD, error) { return cipher.NewGCMWithNonceSize(block, 7) }},
+		{func() (cipher.AEAD, error) { return NewGCMWithTagSize(key, 13) },
+			func() (cipher.AEAD, error) { return cipher.NewGCMWithTagSize(block, 13) }},
+	}
+	for i, c := range ctors {
+		ours, err := c.ours()
+		if err != nil {
+			t.Fatal(err)
+		}
+		std, err := c.std()
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := make([]byte, ours.NonceSize())
+		for j := range nonce {
+			nonce[j] = byte(j * 13)
+		}
+		for _, n := range []int{0, 1, 15, 16, 17, 64, 1000} {
+			msg := bytes.Repeat([]byte{byte(n)}, n)
+			aad := msg[:n/2]
+			want := std.Seal(nil, nonce, msg, aad)
+			got := ours.Seal(nil, nonce, msg, aad)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("constructor %d, %d bytes: Seal = %x, want %x", i, n, got, want)
+			}
+			pt, err := ours.Open(nil, nonce, want, aad)
+			if err != nil || !bytes.Equal(pt, msg) {
+				t.Fatalf("constructor %d, %d bytes: Open failed: %v", i, n, err)
+			}
+		}
+	}
+}
+
+func TestGHASHMul(t *testing.T) {
+	h := gcmFieldElement{0x66e94bd4ef8a2c3b, 0x884cfa59ca342b2e}
+	table := newGHASHTable(h)
+	y := gcmFieldElement{0x0388dace60b6a392, 0xf328c2b971b2fe78}
+	for i := 0; i < 100; i++ {
+		want := gcmMul(y, h)
+		if got := ghashMul(y, h); got != want {
+			t.Fatalf("ghashMul = %x, want %x", got, want)
+		}
+		if got := table.mul(y); got != want {
+			t.Fatalf("table mul = %x, want %x", got, want)
+		}
+		y = gcmFieldElement{want.hi ^ uint64(i), want.lo*3 + 1}
+		h = gcmFieldElement{h.hi*5 + want.lo, h.lo ^ want.hi}
+		table = newGHASHTable(h)
+	}
+	// The extremes exercise every carry and reduction path.
+	ones := gcmFieldElement{^uint64(0), ^uint64(0)}
+	one := gcmFieldElement{hi: 1 << 63}
+	for _, c := range [][2]gcmFieldElement{{ones, ones}, {one, ones}, {ones, one}, {{}, ones}} {
+		if got, want := ghashMul(c[0], c[1]), gcmMul(c[0], c[1]); got != want {
+			t.Errorf("ghashMul(%x, %x) = %x, want %x", c[0], c[1], got, want)
+		}
+	}
+}
+
+func benchmarkGHASH(b *testing.B, mul func(gcmFieldElement) gcmFieldElement) {
+	buf := make([]byte, 16*1024)
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		var y gcmFieldElement
+		for j := 0; j < len(buf); j += 16 {
+			x := loadFieldElement(buf[j:])
+			y.hi ^= x.hi
+			y.lo ^= x.lo
+			y = mul(y)
+		}
+	}
+}
+
+func BenchmarkGHASHTable(b *testing.B) {
+	table := newGHASHTable(gcmFieldElement{0x66e94bd4ef8a2c3b, 0x884cfa59ca342b2e})
+	benchmarkGHASH(b, table.mul)
+}
+
+func BenchmarkGHASHConstantTime(b *testing.B) {
+	h := gcmFieldElement{0x66e94bd4ef8a2c3b, 0x884cfa59ca342b2e}
+	benchmarkGHASH(b, func(y gcmFieldElement) gcmFieldElement { return ghashMul(y, h) })
+}
+
+func BenchmarkGHASHBitwise(b *testing.B) {
+	h := gcmFieldElement{0x66e94bd4ef8a2c3b, 0x884cfa59ca342b2e}
+	benchmarkGHASH(b, func(y gcmFieldElement) gcmFieldElement { return gcmMul(y, h) })
+}
+
+func BenchmarkGCMSeal(b *testing.B) {
+	aead, _ := NewGCM([]byte("1234567890abcdef"))
+	nonce := make([]byte, aead.NonceSize())
+	buf := make([]byte, 16*1024)
+	out := make([]byte, 0, len(buf)+aead.Overhead())
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		aead.Seal(out, nonce, buf, nil)
+	}
+}
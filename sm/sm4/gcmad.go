@@ -0,0 +1,108 @@
+package sm4
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// A GCMMessage seals or opens a single SM4-GCM message, with the 12 byte
+// nonce and 16 byte tag of NewGCM, whose additional data is supplied in
+// pieces through AddAD rather than as one slice. The result is the same as
+// passing the concatenation of those pieces to the AEAD's Seal or Open.
+//
+// A GCMMessage may be used for one Seal or one Open only; AddAD may not be
+// called after either.
+type GCMMessage struct {
+	g       *sm4GCM
+	nonce   [gcmStandardNonceSize]byte
+	y       gcmFieldElement
+	partial [BlockSize]byte // additional data not yet absorbed
+	n       int             // bytes used in partial
+	adLen   uint64
+	done    bool
+}
+
+// NewGCMMessage returns a GCMMessage for the given key and 12 byte nonce.
+func NewGCMMessage(key, nonce []byte) (*GCMMessage, error) {
+	if len(nonce) != gcmStandardNonceSize {
+		return nil, errors.New("sm4: incorrect nonce length given to GCM")
+	}
+	g, err := newGCM(key, gcmStandardNonceSize, gcmTagSize, false)
+	if err != nil {
+		return nil, err
+	}
+	m := &GCMMessage{g: g}
+	copy(m.nonce[:], nonce)
+	return m, nil
+}
+
+// AddAD appends p to the additional data. Blocks are absorbed into GHASH
+// as soon as they are complete, so no more than one block is buffered.
+func (m *GCMMessage) AddAD(p []byte) {
+	if m.done {
+		panic("sm4: GCMMessage.AddAD called after Seal or Open")
+	}
+	m.adLen += uint64(len(p))
+	if m.n > 0 {
+		c := copy(m.partial[m.n:], p)
+		m.n += c
+		p = p[c:]
+		if m.n < BlockSize {
+			return
+		}
+		m.g.ghash.update(&m.y, m.partial[:])
+		m.n = 0
+	}
+	full := len(p) &^ (BlockSize - 1)
+	m.g.ghash.update(&m.y, p[:full])
+	m.n = copy(m.partial[:], p[full:])
+}
+
+// begin finishes the additional data and returns the initial counter and
+// tag mask.
+func (m *GCMMessage) begin() (y gcmFieldElement, counter, tagMask [BlockSize]byte) {
+	if m.done {
+		panic("sm4: GCMMessage used more than once")
+	}
+	m.done = true
+	y = m.y
+	m.g.ghash.update(&y, m.partial[:m.n])
+	m.g.deriveCounter(&counter, m.nonce[:])
+	m.g.cipher.Encrypt(tagMask[:], counter[:])
+	gcmInc32(&counter)
+	return y, counter, tagMask
+}
+
+// Seal encrypts and authenticates plaintext together with the additional
+// data, appending the ciphertext and tag to dst like cipher.AEAD.Seal.
+func (m *GCMMessage) Seal(dst, plaintext []byte) []byte {
+	if uint64(len(plaintext)) > gcmMaxPlaintext {
+		panic("sm4: message too large for GCM")
+	}
+	y, counter, tagMask := m.begin()
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmTagSize)
+	m.g.counterCrypt(out, plaintext, &counter)
+	m.g.finishAuth(out[len(plaintext):], y, m.adLen, out[:len(plaintext)], &tagMask)
+	return ret
+}
+
+// Open authenticates ciphertext together with the additional data and, if
+// both are authentic, decrypts it, appending the plaintext to dst like
+// cipher.AEAD.Open.
+func (m *GCMMessage) Open(dst, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < gcmTagSize || uint64(len(ciphertext)-gcmTagSize) > gcmMaxPlaintext {
+		return nil, errOpen
+	}
+	y, counter, tagMask := m.begin()
+	tag := ciphertext[len(ciphertext)-gcmTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-gcmTagSize]
+
+	var expected [gcmTagSize]byte
+	m.g.finishAuth(expected[:], y, m.adLen, ciphertext, &tagMask)
+	if subtle.ConstantTimeCompare(expected[:], tag) != 1 {
+		return nil, errOpen
+	}
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	m.g.counterCrypt(out, ciphertext, &counter)
+	return ret, nil
+}
@@ -0,0 +1,74 @@
+package sm4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGCMMessage(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	nonce := []byte("twelve bytes")
+	aead, _ := NewGCM(key)
+	ad := make([]byte, 100)
+	for i := range ad {
+		ad[i] = byte(i)
+	}
+	msg := []byte("streamed additional data")
+	want := aead.Seal(nil, nonce, msg, ad)
+
+	for _, chunks := range [][]int{
+		{100},
+		{0, 100},
+		{1, 1, 1, 97},
+		{15, 1, 16, 68},
+		{16, 16, 16, 52},
+		{7, 30, 40, 23},
+		{99, 0, 1},
+	} {
+		m, err := NewGCMMessage(key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rest := ad
+		for _, n := range chunks {
+			m.AddAD(rest[:n])
+			rest = rest[n:]
+		}
+		if got := m.Seal(nil, msg); !bytes.Equal(got, want) {
+			t.Errorf("chunks %v: Seal = %x, want %x", chunks, got, want)
+		}
+
+		m, _ = NewGCMMessage(key, nonce)
+		rest = ad
+		for _, n := range chunks {
+			m.AddAD(rest[:n])
+			rest = rest[n:]
+		}
+		got, err := m.Open(nil, want)
+		if err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("chunks %v: Open = %q, %v", chunks, got, err)
+		}
+	}
+
+	m, _ := NewGCMMessage(key, nonce)
+	m.AddAD(ad[:99])
+	if _, err := m.Open(nil, want); err == nil {
+		t.Error("Open accepted truncated additional data")
+	}
+	m, _ = NewGCMMessage(key, nonce)
+	m.AddAD(ad)
+	if got := m.Seal(nil, nil); !bytes.Equal(got, aead.Seal(nil, nonce, nil, ad)) {
+		t.Error("Seal of an empty message differs from the AEAD")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("AddAD after Seal did not panic")
+			}
+		}()
+		m.AddAD([]byte("late"))
+	}()
+	if _, err := NewGCMMessage(key, nonce[:8]); err == nil {
+		t.Error("NewGCMMessage accepted an 8 byte nonce")
+	}
+}
@@ -0,0 +1,194 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// gcmSIV implements the nonce misuse-resistant AEAD of RFC 8452 with SM4 in
+// place of AES-128. Reusing a nonce only reveals whether two messages with
+// the same nonce and additional data were equal.
+type gcmSIV struct {
+	key      [16]byte
+	newBlock func([]byte) (cipher.Block, error)
+}
+
+const (
+	gcmSIVNonceSize = 12
+	gcmSIVTagSize   = 16
+)
+
+var errOpen = errors.New("sm4: message authentication failed")
+
+// NewGCMSIV returns SM4-GCM-SIV, the construction of RFC 8452 instantiated
+// with SM4 and a 16 byte key-generating key. It uses 12 byte nonces and 16
+// byte tags.
+func NewGCMSIV(key []byte) (cipher.AEAD, error) {
+	return newGCMSIV(key, NewCipher)
+}
+
+func newGCMSIV(key []byte, newBlock func([]byte) (cipher.Block, error)) (*gcmSIV, error) {
+	if len(key) != 16 {
+		return nil, KeySizeError(len(key))
+	}
+	if _, err := newBlock(key); err != nil {
+		return nil, err
+	}
+	g := &gcmSIV{newBlock: newBlock}
+	copy(g.key[:], key)
+	return g, nil
+}
+
+func (g *gcmSIV) NonceSize() int { return gcmSIVNonceSize }
+
+func (g *gcmSIV) Overhead() int { return gcmSIVTagSize }
+
+// deriveKeys returns the per-nonce authentication key and encryption
+// block, RFC 8452 section 4.
+func (g *gcmSIV) deriveKeys(nonce []byte) (authKey [16]byte, enc cipher.Block) {
+	kgk, _ := g.newBlock(g.key[:])
+	var in, out [16]byte
+	var encKey [16]byte
+	copy(in[4:], nonce)
+	for i := uint32(0); i < 4; i++ {
+		binary.LittleEndian.PutUint32(in[:4], i)
+		kgk.Encrypt(out[:], in[:])
+		if i < 2 {
+			copy(authKey[8*i:], out[:8])
+		} else {
+			copy(encKey[8*(i-2):], out[:8])
+		}
+	}
+	enc, _ = g.newBlock(encKey[:])
+	return authKey, enc
+}
+
+// tag computes the synthetic IV over additionalData and plaintext.
+func (g *gcmSIV) tag(authKey *[16]byte, enc cipher.Block, nonce, plaintext, additionalData []byte) [16]byte {
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+
+	p := newPolyval(authKey[:])
+	p.update(additionalData)
+	p.update(plaintext)
+	p.update(lengths[:])
+
+	var s [16]byte
+	p.sum(s[:])
+	for i := range nonce {
+		s[i] ^= nonce[i]
+	}
+	s[15] &= 0x7f
+	enc.Encrypt(s[:], s[:])
+	return s
+}
+
+// gcmSIVCTR XORs src with the RFC 8452 keystream, whose counter block is the tag
+// with the top bit set and a little-endian 32-bit counter in its first
+// four bytes.
+func gcmSIVCTR(enc cipher.Block, tag *[16]byte, dst, src []byte) {
+	var ctr, ks [16]byte
+	ctr = *tag
+	ctr[15] |= 0x80
+	for len(src) > 0 {
+		enc.Encrypt(ks[:], ctr[:])
+		binary.LittleEndian.PutUint32(ctr[:4], binary.LittleEndian.Uint32(ctr[:4])+1)
+		n := subtle.XORBytes(dst, src, ks[:])
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+func (g *gcmSIV) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("sm4: incorrect nonce length given to GCM-SIV")
+	}
+	authKey, enc := g.deriveKeys(nonce)
+	tag := g.tag(&authKey, enc, nonce, plaintext, additionalData)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmSIVTagSize)
+	gcmSIVCTR(enc, &tag, out, plaintext)
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+func (g *gcmSIV) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("sm4: incorrect nonce length given to GCM-SIV")
+	}
+	if len(ciphertext) < gcmSIVTagSize {
+		return nil, errOpen
+	}
+	var tag [16]byte
+	copy(tag[:], ciphertext[len(ciphertext)-gcmSIVTagSize:])
+	ciphertext = ciphertext[:len(ciphertext)-gcmSIVTagSize]
+
+	authKey, enc := g.deriveKeys(nonce)
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	gcmSIVCTR(enc, &tag, out, ciphertext)
+
+	expected := g.tag(&authKey, enc, nonce, out, additionalData)
+	if subtle.ConstantTimeCompare(expected[:], tag[:]) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+// polyval computes POLYVAL (RFC 8452, section 3) through its relation to
+// GHASH given in appendix A: POLYVAL(H, X...) equals
+// ByteReverse(GHASH(mulX_GHASH(ByteReverse(H)), ByteReverse(X)...)).
+type polyval struct {
+	h, s gcmFieldElement
+}
+
+func reverseBlock(dst, src []byte) {
+	for i := 0; i < 16; i++ {
+		dst[i] = src[15-i]
+	}
+}
+
+func newPolyval(key []byte) *polyval {
+	var b [16]byte
+	reverseBlock(b[:], key)
+	return &polyval{h: loadFieldElement(b[:]).mulX()}
+}
+
+// update absorbs data, zero padded to a multiple of 16 bytes.
+func (p *polyval) update(data []byte) {
+	var b, block [16]byte
+	for len(data) > 0 {
+		block = [16]byte{}
+		n := copy(block[:], data)
+		data = data[n:]
+		reverseBlock(b[:], block[:])
+		x := loadFieldElement(b[:])
+		p.s.hi ^= x.hi
+		p.s.lo ^= x.lo
+		p.s = gcmMul(p.s, p.h)
+	}
+}
+
+func (p *polyval) sum(out []byte) {
+	var b [16]byte
+	p.s.store(b[:])
+	reverseBlock(out, b[:])
+}
+
+// sliceForAppend takes a slice and a requested number of bytes. It returns a
+// slice with the contents of the given slice followed by that many bytes and
+// a second slice that aliases into it and contains only the extra bytes.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
@@ -0,0 +1,103 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestPolyval(t *testing.T) {
+	// RFC 8452, appendix A.
+	p := newPolyval(decodeHex("25629347589242761d31f826ba4b757b"))
+	p.update(decodeHex("4f4f95668c83dfb6401762bb2d01a262"))
+	p.update(decodeHex("d1a24ddd2721d006bbe45f20d3c9f362"))
+	out := make([]byte, 16)
+	p.sum(out)
+	if want := decodeHex("f7a3b47b846119fae5b7866cf5e5b77e"); !bytes.Equal(out, want) {
+		t.Errorf("POLYVAL = %x, want %x", out, want)
+	}
+}
+
+func TestGCMSIVConstructionWithAES(t *testing.T) {
+	// The construction is cipher independent, so instantiating it with AES
+	// must reproduce the first AEAD_AES_128_GCM_SIV vector of RFC 8452,
+	// appendix C.1.
+	g, err := newGCMSIV(decodeHex("01000000000000000000000000000000"), aes.NewCipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := decodeHex("030000000000000000000000")
+	got := g.Seal(nil, nonce, nil, nil)
+	if want := decodeHex("dc20e2d83f25705bb49e439eca56de25"); !bytes.Equal(got, want) {
+		t.Errorf("AES-GCM-SIV tag = %x, want %x", got, want)
+	}
+}
+
+func TestGCMSIV(t *testing.T) {
+	aead, err := NewGCMSIV([]byte("1234567890abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	aad := []byte("header")
+	for _, msg := range [][]byte{nil, []byte("short"), bytes.Repeat([]byte("0123456789"), 10)} {
+		ct := aead.Seal(nil, nonce, msg, aad)
+		if len(ct) != len(msg)+aead.Overhead() {
+			t.Fatalf("ciphertext length %d, want %d", len(ct), len(msg)+aead.Overhead())
+		}
+		got, err := aead.Open(nil, nonce, ct, aad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("Open = %q, want %q", got, msg)
+		}
+		for _, i := range []int{0, len(ct) - 1} {
+			bad := append([]byte{}, ct...)
+			bad[i] ^= 1
+			if _, err := aead.Open(nil, nonce, bad, aad); err == nil {
+				t.Errorf("Open accepted a ciphertext modified at byte %d", i)
+			}
+		}
+		if _, err := aead.Open(nil, nonce, ct, []byte("other")); err == nil {
+			t.Error("Open accepted modified additional data")
+		}
+	}
+	if _, err := aead.Open(nil, nonce, make([]byte, 15), nil); err == nil {
+		t.Error("Open accepted a ciphertext shorter than the tag")
+	}
+}
+
+func TestGCMSIVNonceReuse(t *testing.T) {
+	aead, _ := NewGCMSIV([]byte("1234567890abcdef"))
+	nonce := []byte("fixed nonce!")
+	m1 := []byte("attack at dawn!!")
+	m2 := []byte("attack at dusk!!")
+
+	c1 := aead.Seal(nil, nonce, m1, nil)
+	if !bytes.Equal(c1, aead.Seal(nil, nonce, m1, nil)) {
+		t.Error("equal messages under a reused nonce gave different ciphertexts")
+	}
+	c2 := aead.Seal(nil, nonce, m2, nil)
+	// Under GCM a reused nonce gives c1 ^ c2 == m1 ^ m2. Under GCM-SIV the
+	// keystream depends on the message, so this must not hold.
+	x := make([]byte, len(m1))
+	for i := range x {
+		x[i] = c1[i] ^ c2[i] ^ m1[i] ^ m2[i]
+	}
+	if bytes.Equal(x, make([]byte, len(x))) {
+		t.Error("different messages under a reused nonce share a keystream")
+	}
+	if bytes.Equal(c1[len(m1):], c2[len(m2):]) {
+		t.Error("different messages under a reused nonce share a tag")
+	}
+}
@@ -0,0 +1,200 @@
+package sm4
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// gcmFieldElement is an element of GF(2¹²⁸) in the bit order of GCM
+// (NIST SP 800-38D): hi holds bytes 0-7 of the block and lo bytes 8-15, and
+// the coefficient of x⁰ is the most significant bit of hi.
+type gcmFieldElement struct {
+	hi, lo uint64
+}
+
+func loadFieldElement(b []byte) gcmFieldElement {
+	return gcmFieldElement{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:16])}
+}
+
+func (x gcmFieldElement) store(b []byte) {
+	binary.BigEndian.PutUint64(b[:8], x.hi)
+	binary.BigEndian.PutUint64(b[8:16], x.lo)
+}
+
+// mulX returns x·X, reducing modulo X¹²⁸ + X⁷ + X² + X + 1.
+func (x gcmFieldElement) mulX() gcmFieldElement {
+	carry := x.lo & 1
+	x.lo = x.lo>>1 | x.hi<<63
+	x.hi >>= 1
+	x.hi ^= 0xe100000000000000 & -carry
+	return x
+}
+
+// gcmMul returns x·y using the bit-by-bit method of SP 800-38D, Algorithm 1.
+// It runs in time independent of the values of x and y. It is the reference
+// against which ghashMul and ghashTable are tested and benchmarked.
+func gcmMul(x, y gcmFieldElement) gcmFieldElement {
+	var z gcmFieldElement
+	v := y
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = x.hi >> uint(63-i) & 1
+		} else {
+			bit = x.lo >> uint(127-i) & 1
+		}
+		z.hi ^= v.hi & -bit
+		z.lo ^= v.lo & -bit
+		v = v.mulX()
+	}
+	return z
+}
+
+// bmul64 returns the low 64 bits of the carry-less product of x and y. The
+// operands are split into four interleaved sets of bits with three-bit
+// holes between them, so that the carries of the integer multiplications
+// fall into the holes and are masked away. It uses no branches or memory
+// lookups that depend on x or y, following BearSSL's ghash_ctmul64.
+func bmul64(x, y uint64) uint64 {
+	const (
+		m0 = 0x1111111111111111
+		m1 = 0x2222222222222222
+		m2 = 0x4444444444444444
+		m3 = 0x8888888888888888
+	)
+	x0, x1, x2, x3 := x&m0, x&m1, x&m2, x&m3
+	y0, y1, y2, y3 := y&m0, y&m1, y&m2, y&m3
+	z0 := x0*y0 ^ x1*y3 ^ x2*y2 ^ x3*y1
+	z1 := x0*y1 ^ x1*y0 ^ x2*y3 ^ x3*y2
+	z2 := x0*y2 ^ x1*y1 ^ x2*y0 ^ x3*y3
+	z3 := x0*y3 ^ x1*y2 ^ x2*y1 ^ x3*y0
+	return z0&m0 | z1&m1 | z2&m2 | z3&m3
+}
+
+// ghashMul returns x·y in constant time. The 128-bit carry-less product is
+// assembled by Karatsuba from bmul64 on the words and on their bit
+// reversals, which yield the high halves, and then reduced modulo
+// X¹²⁸ + X⁷ + X² + X + 1. It is the multiplication used by NewGCM and its
+// variants.
+func ghashMul(x, y gcmFieldElement) gcmFieldElement {
+	x0, x1 := x.lo, x.hi
+	y0, y1 := y.lo, y.hi
+	x0r, x1r := bits.Reverse64(x0), bits.Reverse64(x1)
+	y0r, y1r := bits.Reverse64(y0), bits.Reverse64(y1)
+	x2, x2r := x0^x1, x0r^x1r
+	y2, y2r := y0^y1, y0r^y1r
+
+	z0 := bmul64(x0, y0)
+	z1 := bmul64(x1, y1)
+	z2 := bmul64(x2, y2)
+	z0h := bmul64(x0r, y0r)
+	z1h := bmul64(x1r, y1r)
+	z2h := bmul64(x2r, y2r)
+	z2 ^= z0 ^ z1
+	z2h ^= z0h ^ z1h
+	z0h = bits.Reverse64(z0h) >> 1
+	z1h = bits.Reverse64(z1h) >> 1
+	z2h = bits.Reverse64(z2h) >> 1
+
+	v0, v1, v2, v3 := z0, z0h^z2, z1^z2h, z1h
+	v3 = v3<<1 | v2>>63
+	v2 = v2<<1 | v1>>63
+	v1 = v1<<1 | v0>>63
+	v0 <<= 1
+
+	v2 ^= v0 ^ v0>>1 ^ v0>>2 ^ v0>>7
+	v1 ^= v0<<63 ^ v0<<62 ^ v0<<57
+	v3 ^= v1 ^ v1>>1 ^ v1>>2 ^ v1>>7
+	v2 ^= v1<<63 ^ v1<<62 ^ v1<<57
+	return gcmFieldElement{hi: v3, lo: v2}
+}
+
+// ghashTable holds the products of H with every 4-bit polynomial, for
+// Shoup's table-driven multiplication by H. The index of each entry is the
+// polynomial with its bits reversed, so that the low nibble of a word in GCM
+// bit order selects it directly. It is only used by NewGCMWithTable.
+//
+// Unlike ghashMul, multiplication through the table makes memory accesses
+// that depend on the data and on H, and may therefore leak the
+// authentication key through cache timing on shared hardware.
+type ghashTable [16]gcmFieldElement
+
+// ghashReduction holds the reduction of each 4-bit value shifted off the
+// end of a field element, pre-shifted to the top 16 bits of hi.
+var ghashReduction = [16]uint16{
+	0x0000, 0x1c20, 0x3840, 0x2460, 0x7080, 0x6ca0, 0x48c0, 0x54e0,
+	0xe100, 0xfd20, 0xd940, 0xc560, 0x9180, 0x8da0, 0xa9c0, 0xb5e0,
+}
+
+func reverseNibble(i int) int {
+	return i&1<<3 | i&2<<1 | i&4>>1 | i&8>>3
+}
+
+func newGHASHTable(h gcmFieldElement) *ghashTable {
+	t := new(ghashTable)
+	t[reverseNibble(1)] = h
+	for i := 2; i < 16; i += 2 {
+		t[reverseNibble(i)] = t[reverseNibble(i/2)].mulX()
+		x := t[reverseNibble(i)]
+		t[reverseNibble(i+1)] = gcmFieldElement{x.hi ^ h.hi, x.lo ^ h.lo}
+	}
+	return t
+}
+
+// mul returns y·H.
+func (t *ghashTable) mul(y gcmFieldElement) gcmFieldElement {
+	var z gcmFieldElement
+	for i := 0; i < 2; i++ {
+		word := y.lo
+		if i == 1 {
+			word = y.hi
+		}
+		// Multiply z by X⁴ and add the multiple of H for the next nibble.
+		for j := 0; j < 64; j += 4 {
+			msw := z.lo & 0xf
+			z.lo = z.lo>>4 | z.hi<<60
+			z.hi >>= 4
+			z.hi ^= uint64(ghashReduction[msw]) << 48
+
+			p := &t[word&0xf]
+			z.hi ^= p.hi
+			z.lo ^= p.lo
+			word >>= 4
+		}
+	}
+	return z
+}
+
+// ghashKey multiplies by the GHASH key H, in constant time through
+// ghashMul unless table is set.
+type ghashKey struct {
+	h     gcmFieldElement
+	table *ghashTable
+}
+
+// mul returns y·H.
+func (k *ghashKey) mul(y gcmFieldElement) gcmFieldElement {
+	if k.table != nil {
+		return k.table.mul(y)
+	}
+	return ghashMul(y, k.h)
+}
+
+// update absorbs data into the GHASH state y, zero padding the final block.
+func (k *ghashKey) update(y *gcmFieldElement, data []byte) {
+	for len(data) >= 16 {
+		x := loadFieldElement(data)
+		y.hi ^= x.hi
+		y.lo ^= x.lo
+		*y = k.mul(*y)
+		data = data[16:]
+	}
+	if len(data) > 0 {
+		var block [16]byte
+		copy(block[:], data)
+		x := loadFieldElement(block[:])
+		y.hi ^= x.hi
+		y.lo ^= x.lo
+		*y = k.mul(*y)
+	}
+}
@@ -0,0 +1,85 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// keyWrapIV is the default initial value of RFC 3394, section 2.2.3.1.
+var keyWrapIV = [8]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+var (
+	errKeyWrapLength = errors.New("sm4: key to wrap must be a multiple of 8 bytes and at least 16 bytes")
+	errKeyUnwrap     = errors.New("sm4: key unwrap failed")
+)
+
+// WrapKey wraps key under the key encryption key kek using the RFC 3394 key
+// wrap algorithm with SM4 as the block cipher. The result is 8 bytes longer
+// than key. key must be a multiple of 8 bytes and at least 16 bytes long.
+func WrapKey(kek, key []byte) ([]byte, error) {
+	c, err := NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return wrapKey(c, key)
+}
+
+// UnwrapKey reverses WrapKey, returning an error if wrapped was not produced
+// by WrapKey under kek or has been modified.
+func UnwrapKey(kek, wrapped []byte) ([]byte, error) {
+	c, err := NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	return unwrapKey(c, wrapped)
+}
+
+func wrapKey(b cipher.Block, key []byte) ([]byte, error) {
+	if len(key)%8 != 0 || len(key) < 16 {
+		return nil, errKeyWrapLength
+	}
+	n := len(key) / 8
+	out := make([]byte, 8+len(key))
+	copy(out, keyWrapIV[:])
+	copy(out[8:], key)
+
+	var block [16]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(block[:8], out[:8])
+			copy(block[8:], out[8*i:])
+			b.Encrypt(block[:], block[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(block[:8])^t)
+			copy(out[8*i:], block[8:])
+		}
+	}
+	return out, nil
+}
+
+func unwrapKey(b cipher.Block, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, errKeyUnwrap
+	}
+	n := len(wrapped)/8 - 1
+	out := make([]byte, len(wrapped))
+	copy(out, wrapped)
+
+	var block [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(block[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(block[8:], out[8*i:])
+			b.Decrypt(block[:], block[:])
+			copy(out[:8], block[:8])
+			copy(out[8*i:], block[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(out[:8], keyWrapIV[:]) != 1 {
+		return nil, errKeyUnwrap
+	}
+	return out[8:], nil
+}
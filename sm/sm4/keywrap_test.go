@@ -0,0 +1,74 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+func TestWrapKey(t *testing.T) {
+	kek := []byte("1234567890abcdef")
+	for _, n := range []int{16, 24, 32, 64} {
+		key := make([]byte, n)
+		for i := range key {
+			key[i] = byte(i)
+		}
+		wrapped, err := WrapKey(kek, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(wrapped) != n+8 {
+			t.Errorf("wrapped %d byte key is %d bytes, want %d", n, len(wrapped), n+8)
+		}
+		got, err := UnwrapKey(kek, wrapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Errorf("UnwrapKey = %x, want %x", got, key)
+		}
+
+		for i := range wrapped {
+			bad := append([]byte{}, wrapped...)
+			bad[i] ^= 0x80
+			if _, err := UnwrapKey(kek, bad); err == nil {
+				t.Fatalf("UnwrapKey accepted a key corrupted at byte %d", i)
+			}
+		}
+		if _, err := UnwrapKey([]byte("fedcba0987654321"), wrapped); err == nil {
+			t.Error("UnwrapKey succeeded with the wrong KEK")
+		}
+	}
+
+	for _, n := range []int{0, 8, 17} {
+		if _, err := WrapKey(kek, make([]byte, n)); err == nil {
+			t.Errorf("WrapKey accepted a %d byte key", n)
+		}
+	}
+	if _, err := UnwrapKey(kek, make([]byte, 16)); err == nil {
+		t.Error("UnwrapKey accepted a 16 byte input")
+	}
+}
+
+// The wrapping algorithm itself is checked against RFC 3394, section 4.1,
+// which uses AES-128.
+func TestWrapKeyRFC3394(t *testing.T) {
+	kek := decodeHex("000102030405060708090A0B0C0D0E0F")
+	key := decodeHex("00112233445566778899AABBCCDDEEFF")
+	want := decodeHex("1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5")
+	b, err := aes.NewCipher(kek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := wrapKey(b, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("wrapKey = %X, want %X", got, want)
+	}
+	unwrapped, err := unwrapKey(b, want)
+	if err != nil || !bytes.Equal(unwrapped, key) {
+		t.Errorf("unwrapKey = %X, %v, want %X", unwrapped, err, key)
+	}
+}
@@ -0,0 +1,26 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+// NewOFBAt returns an SM4 OFB stream keyed with key whose keystream starts
+// at block blockOffset of the stream that cipher.NewOFB would produce for
+// iv. OFB feedback cannot be computed out of order, so seeking costs
+// blockOffset block encryptions.
+func NewOFBAt(key, iv []byte, blockOffset uint64) (cipher.Stream, error) {
+	if len(iv) != BlockSize {
+		return nil, errors.New("sm4: IV length must equal block size")
+	}
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	reg := make([]byte, BlockSize)
+	copy(reg, iv)
+	for i := uint64(0); i < blockOffset; i++ {
+		c.Encrypt(reg, reg)
+	}
+	return cipher.NewOFB(c, reg), nil
+}
@@ -0,0 +1,39 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestNewOFBAt(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	iv := []byte("fedcba0987654321")
+
+	const blocks = 40
+	full := make([]byte, blocks*BlockSize)
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher.NewOFB(c, iv).XORKeyStream(full, full)
+
+	for _, k := range []uint64{0, 1, 2, 17, blocks - 1} {
+		s, err := NewOFBAt(key, iv, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, len(full)-int(k)*BlockSize)
+		s.XORKeyStream(got, got)
+		if want := full[k*BlockSize:]; !bytes.Equal(got, want) {
+			t.Errorf("keystream at block %d = %x, want %x", k, got[:BlockSize], want[:BlockSize])
+		}
+	}
+
+	if _, err := NewOFBAt(key, iv[:8], 0); err == nil {
+		t.Error("NewOFBAt accepted a short IV")
+	}
+	if _, err := NewOFBAt(key[:8], iv, 0); err == nil {
+		t.Error("NewOFBAt accepted a short key")
+	}
+}
@@ -0,0 +1,110 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+// SIVKeySize is the size of the key taken by DeterministicSeal and
+// DeterministicOpen.
+const SIVKeySize = 32
+
+// DeterministicSeal encrypts and authenticates plaintext and aad with
+// SIV mode (RFC 5297) built on SM4. It uses CMAC-SM4 for S2V and SM4-CTR
+// for encryption. key is SIVKeySize bytes. As in RFC 5297, the first half
+// is the CMAC key and the second half the CTR key. The result is the
+// 16 byte synthetic IV followed by the ciphertext, which is as long as
+// plaintext.
+//
+// No nonce is used, so sealing the same plaintext and aad under the same
+// key always gives the same output. That makes encrypted blocks
+// deduplicable, but it also shows an observer which messages are equal,
+// and someone who can guess a plaintext can confirm the guess by sealing
+// it. Use it only where revealing equality is acceptable. Otherwise, use
+// an AEAD with a unique nonce per message, such as NewGCM or NewGCMSIV.
+func DeterministicSeal(key, aad, plaintext []byte) ([]byte, error) {
+	mac, ctr, err := sivCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+	v := s2v(mac, aad, plaintext)
+	out := make([]byte, BlockSize+len(plaintext))
+	copy(out, v[:])
+	sivCTR(ctr, v, out[BlockSize:], plaintext)
+	return out, nil
+}
+
+// DeterministicOpen decrypts and authenticates the output of
+// DeterministicSeal, returning the plaintext only if it and aad are
+// authentic.
+func DeterministicOpen(key, aad, ciphertext []byte) ([]byte, error) {
+	mac, ctr, err := sivCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < BlockSize {
+		return nil, errOpen
+	}
+	var v [BlockSize]byte
+	copy(v[:], ciphertext)
+	pt := make([]byte, len(ciphertext)-BlockSize)
+	sivCTR(ctr, v, pt, ciphertext[BlockSize:])
+	expected := s2v(mac, aad, pt)
+	if subtle.ConstantTimeCompare(expected[:], v[:]) != 1 {
+		for i := range pt {
+			pt[i] = 0
+		}
+		return nil, errOpen
+	}
+	return pt, nil
+}
+
+func sivCiphers(key []byte) (mac, ctr cipher.Block, err error) {
+	if len(key) != SIVKeySize {
+		return nil, nil, errors.New("sm4: SIV key must be 32 bytes")
+	}
+	if mac, err = NewCipher(key[:16]); err != nil {
+		return nil, nil, err
+	}
+	if ctr, err = NewCipher(key[16:]); err != nil {
+		return nil, nil, err
+	}
+	return mac, ctr, nil
+}
+
+// s2v computes the synthetic IV of RFC 5297, section 2.4, over the vector
+// of strings (aad, plaintext).
+func s2v(b cipher.Block, aad, plaintext []byte) [BlockSize]byte {
+	var zero [BlockSize]byte
+	d := cmacSum(b, zero[:])
+	m := cmacSum(b, aad)
+	d = cmacDouble(d)
+	subtle.XORBytes(d[:], d[:], m[:])
+
+	var t []byte
+	if len(plaintext) >= BlockSize {
+		// T = plaintext xorend D.
+		t = append([]byte{}, plaintext...)
+		tail := t[len(t)-BlockSize:]
+		subtle.XORBytes(tail, tail, d[:])
+	} else {
+		// T = dbl(D) xor pad(plaintext).
+		d = cmacDouble(d)
+		var padded [BlockSize]byte
+		copy(padded[:], plaintext)
+		padded[len(plaintext)] = 0x80
+		subtle.XORBytes(d[:], d[:], padded[:])
+		t = d[:]
+	}
+	return cmacSum(b, t)
+}
+
+// sivCTR XORs src with the CTR keystream for the synthetic IV v, whose top
+// bits in each of its last two 32-bit words are cleared to form the
+// initial counter as RFC 5297 specifies.
+func sivCTR(b cipher.Block, v [BlockSize]byte, dst, src []byte) {
+	v[8] &= 0x7f
+	v[12] &= 0x7f
+	cipher.NewCTR(b, v[:]).XORKeyStream(dst, src)
+}
@@ -0,0 +1,74 @@
+package sm4
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+// TestS2VAES checks the S2V and CTR steps against the deterministic
+// authenticated encryption example of RFC 5297, appendix A.1, by running
+// them with AES. RFC 5297 gives no vectors for SM4.
+func TestS2VAES(t *testing.T) {
+	key := decodeHex("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	aad := decodeHex("101112131415161718191a1b1c1d1e1f2021222324252627")
+	pt := decodeHex("112233445566778899aabbccddee")
+	mac, _ := aes.NewCipher(key[:16])
+	ctr, _ := aes.NewCipher(key[16:])
+
+	v := s2v(mac, aad, pt)
+	if want := decodeHex("85632d07c6e8f37f950acd320a2ecc93"); !bytes.Equal(v[:], want) {
+		t.Errorf("S2V = %x, want %x", v, want)
+	}
+	ct := make([]byte, len(pt))
+	sivCTR(ctr, v, ct, pt)
+	if want := decodeHex("40c02b9690c4dc04daef7f6afe5c"); !bytes.Equal(ct, want) {
+		t.Errorf("ciphertext = %x, want %x", ct, want)
+	}
+}
+
+func TestDeterministicSeal(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789ABCDEF")
+	aad := []byte("block 42")
+	for _, n := range []int{0, 1, 15, 16, 17, 100} {
+		pt := bytes.Repeat([]byte{'d'}, n)
+		sealed, err := DeterministicSeal(key, aad, pt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sealed) != BlockSize+n {
+			t.Errorf("%d bytes sealed to %d", n, len(sealed))
+		}
+		again, _ := DeterministicSeal(key, aad, pt)
+		if !bytes.Equal(sealed, again) {
+			t.Errorf("%d bytes: sealing twice gave different output", n)
+		}
+		got, err := DeterministicOpen(key, aad, sealed)
+		if err != nil || !bytes.Equal(got, pt) {
+			t.Errorf("%d bytes: DeterministicOpen = %x, %v", n, got, err)
+		}
+
+		for i := range sealed {
+			bad := append([]byte{}, sealed...)
+			bad[i] ^= 1
+			if _, err := DeterministicOpen(key, aad, bad); err == nil {
+				t.Fatalf("%d bytes: accepted a change at byte %d", n, i)
+			}
+		}
+		if _, err := DeterministicOpen(key, []byte("block 43"), sealed); err == nil {
+			t.Errorf("%d bytes: accepted different additional data", n)
+		}
+	}
+
+	a, _ := DeterministicSeal(key, aad, []byte("one"))
+	b, _ := DeterministicSeal(key, []byte("other"), []byte("one"))
+	if bytes.Equal(a, b) {
+		t.Error("different additional data gave the same output")
+	}
+	if _, err := DeterministicOpen(key, aad, a[:BlockSize-1]); err == nil {
+		t.Error("DeterministicOpen accepted a message shorter than the IV")
+	}
+	if _, err := DeterministicSeal(key[:16], aad, nil); err == nil {
+		t.Error("DeterministicSeal accepted a 16 byte key")
+	}
+}
@@ -9,16 +9,26 @@ package sm4
 
 import (
 	"bytes"
+	"crypto/cipher"
+	"crypto/subtle"
 	"errors"
 )
 
-type cryptMode int
+// CipherMode selects whether Sm4Ecb and Sm4EcbInPlace encrypt or decrypt.
+type CipherMode int
 
 const BlockSize = 16
 
 const (
-	ENC cryptMode = iota
-	DEC
+	Encrypt CipherMode = iota
+	Decrypt
+)
+
+// ENC and DEC are the original names of Encrypt and Decrypt, kept with the
+// same values for existing callers.
+const (
+	ENC = Encrypt
+	DEC = Decrypt
 )
 
 var sbox = [16][16]byte{
@@ -99,24 +109,42 @@ func keyExp(key [4]uint32) [32]uint32 {
 }
 
 func encrypt_oneround(rk [32]uint32, msg []byte) []byte {
-	var x [36]uint32
 	var cipher = make([]byte, 16)
+	cryptBlock(&rk, cipher, msg)
+	return cipher
+}
+
+// cryptBlockGeneric runs the 32 rounds of SM4 over src using the round
+// keys rk and writes the result to dst. dst and src may overlap entirely.
+// It is the portable implementation behind cryptBlock.
+func cryptBlockGeneric(rk *[32]uint32, dst, src []byte) {
+	var x [36]uint32
 	for i := 0; i < 4; i++ {
-		x[i] = (uint32(msg[i*4+3])) |
-			(uint32(msg[i*4+2]) << 8) |
-			(uint32(msg[i*4+1]) << 16) |
-			(uint32(msg[i*4]) << 24)
+		x[i] = (uint32(src[i*4+3])) |
+			(uint32(src[i*4+2]) << 8) |
+			(uint32(src[i*4+1]) << 16) |
+			(uint32(src[i*4]) << 24)
 	}
 	for i := 0; i < 32; i++ {
 		x[i+4] = x[i] ^ t3(x[i+1]^x[i+2]^x[i+3]^rk[i])
 	}
 	for i := 0; i < 4; i++ {
-		cipher[i*4] = byte(x[35-i]>>24) & 0xff
-		cipher[i*4+1] = byte(x[35-i]>>16) & 0xff
-		cipher[i*4+2] = byte(x[35-i]>>8) & 0xff
-		cipher[i*4+3] = byte(x[35-i]) & 0xff
+		dst[i*4] = byte(x[35-i]>>24) & 0xff
+		dst[i*4+1] = byte(x[35-i]>>16) & 0xff
+		dst[i*4+2] = byte(x[35-i]>>8) & 0xff
+		dst[i*4+3] = byte(x[35-i]) & 0xff
 	}
-	return cipher
+}
+
+func keyToUint32(key []byte) [4]uint32 {
+	var key_u32 [4]uint32
+	for i := 0; i < 4; i++ {
+		key_u32[i] = (uint32(key[i*4+3])) |
+			(uint32(key[i*4+2]) << 8) |
+			(uint32(key[i*4+1]) << 16) |
+			(uint32(key[i*4]) << 24)
+	}
+	return key_u32
 }
 
 func rk_swap(rk [32]uint32) [32]uint32 {
@@ -135,42 +163,50 @@ func pkcs7Padding(src []byte) []byte {
 	return append(src, padtext...)
 }
 
+var errPkcs7Padding = errors.New("Invalid pkcs7 padding")
+
+// pkcs7UnPadding strips PKCS#7 padding from src. To avoid acting as a
+// padding oracle it inspects the whole final block whatever the padding
+// length claims to be, without branching on the values of the pad bytes,
+// and reports every kind of malformed padding with the same error.
 func pkcs7UnPadding(src []byte) ([]byte, error) {
 	length := len(src)
+	if length == 0 || length%BlockSize != 0 {
+		return nil, errPkcs7Padding
+	}
 	unpadding := int(src[length-1])
 
-	if unpadding > BlockSize || unpadding == 0 {
-		return nil, errors.New("Invalid pkcs7 padding (unpadding > BlockSize || unpadding == 0)")
+	good := subtle.ConstantTimeLessOrEq(1, unpadding) &
+		subtle.ConstantTimeLessOrEq(unpadding, BlockSize)
+	tail := src[length-BlockSize:]
+	for i := 0; i < BlockSize; i++ {
+		// The i-th byte from the end must equal unpadding iff i < unpadding.
+		inPad := subtle.ConstantTimeLessOrEq(i+1, unpadding)
+		eq := subtle.ConstantTimeByteEq(tail[BlockSize-1-i], byte(unpadding))
+		good &= subtle.ConstantTimeSelect(inPad, eq, 1)
 	}
-
-	pad := src[len(src)-unpadding:]
-	for i := 0; i < unpadding; i++ {
-		if pad[i] != byte(unpadding) {
-			return nil, errors.New("Invalid pkcs7 padding (pad[i] != unpadding)")
-		}
+	if good != 1 {
+		return nil, errPkcs7Padding
 	}
-
 	return src[:(length - unpadding)], nil
 }
 
-func Sm4Ecb(key []byte, msg []byte, mode cryptMode) []byte {
+// Sm4Ecb encrypts or decrypts msg in ECB mode with PKCS #7 padding. It
+// always pads when encrypting, adding a whole block to block-aligned input;
+// use Sm4EcbRaw for protocols that must not be padded. Decryption returns
+// nil if the padding is invalid. Sm4EcbPadded does the same but reports
+// errors instead.
+func Sm4Ecb(key []byte, msg []byte, mode CipherMode) []byte {
 	var inData []byte
-	if mode == ENC {
+	if mode == Encrypt {
 		inData = pkcs7Padding(msg)
 	} else {
 		inData = msg
 	}
-	var key_u32 [4]uint32
 	cipher := make([]byte, len(inData))
-	for i := 0; i < 4; i++ {
-		key_u32[i] = (uint32(key[i*4+3])) |
-			(uint32(key[i*4+2]) << 8) |
-			(uint32(key[i*4+1]) << 16) |
-			(uint32(key[i*4]) << 24)
-	}
 	var rk [32]uint32
-	rk = keyExp(key_u32)
-	if mode == DEC {
+	rk = keyExp(keyToUint32(key))
+	if mode == Decrypt {
 		rk = rk_swap(rk)
 	}
 	for i := 0; i < len(inData)/16; i++ {
@@ -178,12 +214,73 @@ func Sm4Ecb(key []byte, msg []byte, mode cryptMode) []byte {
 		cipher_tmp := encrypt_oneround(rk, msg_tmp)
 		copy(cipher[i*16:i*16+16], cipher_tmp)
 	}
-	if mode == DEC {
+	if mode == Decrypt {
 		cipher, _ = pkcs7UnPadding(cipher)
 	}
 	return cipher
 }
 
+// Sm4EcbRaw encrypts or decrypts data in ECB mode under key without any
+// padding, returning a new slice of the same length. len(data) must be a
+// multiple of BlockSize.
+func Sm4EcbRaw(key, data []byte, mode CipherMode) ([]byte, error) {
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte{}, data...)
+	if err := Sm4EcbInPlace(c, out, mode); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Sm4EcbPadded is Sm4Ecb with errors: it adds PKCS #7 padding before
+// encrypting and removes it after decrypting, and reports an invalid key,
+// a ciphertext that is not a whole number of blocks or invalid padding.
+func Sm4EcbPadded(key, data []byte, mode CipherMode) ([]byte, error) {
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case Encrypt:
+		out := pkcs7Padding(append([]byte{}, data...))
+		Sm4EcbInPlace(c, out, Encrypt)
+		return out, nil
+	case Decrypt:
+		out := append([]byte{}, data...)
+		if err := Sm4EcbInPlace(c, out, Decrypt); err != nil {
+			return nil, err
+		}
+		return pkcs7UnPadding(out)
+	}
+	return nil, errors.New("sm4: unknown crypt mode")
+}
+
+// Sm4EcbInPlace encrypts or decrypts data in ECB mode with c, overwriting
+// data with the result. Unlike Sm4Ecb it neither adds nor strips padding, so
+// len(data) must be a multiple of BlockSize.
+func Sm4EcbInPlace(c cipher.Block, data []byte, mode CipherMode) error {
+	var crypt func(dst, src []byte)
+	switch mode {
+	case Encrypt:
+		crypt = c.Encrypt
+	case Decrypt:
+		crypt = c.Decrypt
+	default:
+		return errors.New("sm4: unknown crypt mode")
+	}
+	if len(data)%BlockSize != 0 {
+		return errors.New("sm4: input not a multiple of the block size")
+	}
+	for i := 0; i < len(data); i += BlockSize {
+		block := data[i : i+BlockSize]
+		crypt(block, block)
+	}
+	return nil
+}
+
 func leftRotate(x uint32, r int) uint32 {
 	var rr uint32 = uint32(r)
 	return ((x << rr) | (x >> (32 - rr))) & 0xffffffff
@@ -22,11 +22,104 @@ func TestEncAndDec(t *testing.T) {
 	}
 }
 
+func TestCipherMode(t *testing.T) {
+	if ENC != Encrypt || DEC != Decrypt || int(Encrypt) != 0 || int(Decrypt) != 1 {
+		t.Fatal("CipherMode constants changed value")
+	}
+	key := []byte("1234567890abcdef")
+	msg := []byte("this is a test")
+	enc := Sm4Ecb(key, msg, Encrypt)
+	if !bytes.Equal(enc, Sm4Ecb(key, msg, ENC)) {
+		t.Error("Encrypt and ENC disagree")
+	}
+	if !bytes.Equal(Sm4Ecb(key, enc, Decrypt), msg) {
+		t.Error("Decrypt did not invert Encrypt")
+	}
+}
+
+func TestPkcs7UnPadding(t *testing.T) {
+	block := []byte("0123456789abcdef")
+	for n := 1; n <= BlockSize; n++ {
+		padded := pkcs7Padding(append([]byte{}, block[:BlockSize-n]...))
+		if len(padded) != BlockSize {
+			t.Fatalf("padding %d bytes gave length %d", BlockSize-n, len(padded))
+		}
+		got, err := pkcs7UnPadding(padded)
+		if err != nil {
+			t.Fatalf("unpadding %x: %v", padded, err)
+		}
+		if !bytes.Equal(got, block[:BlockSize-n]) {
+			t.Errorf("pkcs7UnPadding(%x) = %x, want %x", padded, got, block[:BlockSize-n])
+		}
+		if n > 1 {
+			bad := append([]byte{}, padded...)
+			bad[BlockSize-n] ^= 0x40
+			if _, err := pkcs7UnPadding(bad); err != errPkcs7Padding {
+				t.Errorf("pkcs7UnPadding(%x) err = %v, want errPkcs7Padding", bad, err)
+			}
+		}
+	}
+	full := pkcs7Padding(append([]byte{}, block...))
+	if got, err := pkcs7UnPadding(full); err != nil || !bytes.Equal(got, block) {
+		t.Errorf("pkcs7UnPadding of a full padding block = %x, %v", got, err)
+	}
+
+	bad := [][]byte{
+		nil,
+		block[:15],
+		append(append([]byte{}, block[:15]...), 0),
+		append(append([]byte{}, block[:15]...), 17),
+		append(block[:0:0], bytes.Repeat([]byte{0xff}, 32)...),
+	}
+	for _, b := range bad {
+		if _, err := pkcs7UnPadding(b); err != errPkcs7Padding {
+			t.Errorf("pkcs7UnPadding(%x) err = %v, want errPkcs7Padding", b, err)
+		}
+	}
+}
+
+func TestSm4EcbInPlace(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("exactly thirty-two bytes long!!!")
+	want := Sm4Ecb(key, msg, ENC)[:len(msg)]
+
+	data := append([]byte{}, msg...)
+	if err := Sm4EcbInPlace(c, data, ENC); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("in-place encryption = %x, want %x", data, want)
+	}
+	if err := Sm4EcbInPlace(c, data, DEC); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, msg) {
+		t.Errorf("in-place decryption = %q, want %q", data, msg)
+	}
+
+	if err := Sm4EcbInPlace(c, make([]byte, 17), ENC); err == nil {
+		t.Error("Sm4EcbInPlace accepted a partial block")
+	}
+	if err := Sm4EcbInPlace(c, make([]byte, 16), CipherMode(7)); err == nil {
+		t.Error("Sm4EcbInPlace accepted an unknown mode")
+	}
+	if err := Sm4EcbInPlace(c, nil, CipherMode(7)); err == nil {
+		t.Error("Sm4EcbInPlace accepted an unknown mode with no data")
+	}
+}
+
 var buf = make([]byte, 8192)
 
 func BenchmarkSm4Ecb(b *testing.B) {
-	b.SetBytes(8)
-
+	c, _ := NewCipher([]byte("1234567890abcdef"))
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		c.Encrypt(buf[:BlockSize], buf[:BlockSize])
+	}
 }
 
 func benchmarkSize(b *testing.B, size int) {
@@ -48,3 +141,70 @@ func BenchmarkSm4Ecb1K(b *testing.B) {
 func BenchmarkSm4Ecb8K(b *testing.B) {
 	benchmarkSize(b, 8192)
 }
+
+func TestSm4EcbRaw(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	for _, n := range []int{0, 16, 48} {
+		msg := bytes.Repeat([]byte{'r'}, n)
+		enc, err := Sm4EcbRaw(key, msg, Encrypt)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if len(enc) != n {
+			t.Errorf("%d bytes encrypted to %d", n, len(enc))
+		}
+		if want := Sm4Ecb(key, msg, Encrypt)[:n]; !bytes.Equal(enc, want) {
+			t.Errorf("%d bytes: Sm4EcbRaw = %x, want %x", n, enc, want)
+		}
+		dec, err := Sm4EcbRaw(key, enc, Decrypt)
+		if err != nil || !bytes.Equal(dec, msg) {
+			t.Errorf("%d bytes: decryption = %x, %v", n, dec, err)
+		}
+	}
+	for _, n := range []int{1, 15, 17, 31} {
+		if _, err := Sm4EcbRaw(key, make([]byte, n), Encrypt); err == nil {
+			t.Errorf("Sm4EcbRaw encrypted %d bytes", n)
+		}
+		if _, err := Sm4EcbRaw(key, make([]byte, n), Decrypt); err == nil {
+			t.Errorf("Sm4EcbRaw decrypted %d bytes", n)
+		}
+	}
+	if _, err := Sm4EcbRaw(key[:15], make([]byte, 16), Encrypt); err == nil {
+		t.Error("Sm4EcbRaw accepted a 15 byte key")
+	}
+	if _, err := Sm4EcbRaw(key, nil, CipherMode(7)); err == nil {
+		t.Error("Sm4EcbRaw accepted an unknown mode with no data")
+	}
+}
+
+func TestSm4EcbPadded(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	for _, n := range []int{0, 1, 16, 33} {
+		msg := bytes.Repeat([]byte{'p'}, n)
+		enc, err := Sm4EcbPadded(key, msg, Encrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(enc, Sm4Ecb(key, msg, Encrypt)) {
+			t.Errorf("%d bytes: Sm4EcbPadded and Sm4Ecb disagree", n)
+		}
+		dec, err := Sm4EcbPadded(key, enc, Decrypt)
+		if err != nil || !bytes.Equal(dec, msg) {
+			t.Errorf("%d bytes: decryption = %x, %v", n, dec, err)
+		}
+	}
+
+	raw, _ := Sm4EcbRaw(key, make([]byte, 16), Encrypt)
+	for name, ct := range map[string][]byte{
+		"empty":       nil,
+		"misaligned":  make([]byte, 17),
+		"bad padding": raw,
+	} {
+		if _, err := Sm4EcbPadded(key, ct, Decrypt); err == nil {
+			t.Errorf("%s: Sm4EcbPadded decrypted without error", name)
+		}
+	}
+	if _, err := Sm4EcbPadded(key, nil, CipherMode(7)); err == nil {
+		t.Error("Sm4EcbPadded accepted an unknown mode")
+	}
+}
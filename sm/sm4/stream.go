@@ -0,0 +1,63 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"sync"
+)
+
+// SM4Stream seals a sequence of records under one SM4-GCM key, numbering
+// them 0, 1, 2, ... and using the record number as the nonce, so nonces
+// never repeat within a stream. The 12 byte nonce for record seq is four
+// zero bytes followed by seq as a big-endian uint64. SealNext is safe for
+// concurrent use.
+//
+// Each key must be used by a single SM4Stream for its whole life: two
+// streams under the same key reuse every nonce. Detecting replayed or
+// reordered records is up to the caller, who can track which seq values
+// it has opened.
+type SM4Stream struct {
+	aead cipher.AEAD
+	mu   sync.Mutex
+	next uint64
+	done bool
+}
+
+// NewSM4Stream returns an SM4Stream keyed with key whose first record is
+// number 0.
+func NewSM4Stream(key []byte) (*SM4Stream, error) {
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &SM4Stream{aead: aead}, nil
+}
+
+// SealNext encrypts and authenticates the next record and returns its
+// number with the ciphertext. It panics once 2⁶⁴ records have been sealed.
+func (s *SM4Stream) SealNext(plaintext, additionalData []byte) (seq uint64, ciphertext []byte) {
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		panic("sm4: stream sequence number exhausted")
+	}
+	seq = s.next
+	s.next++
+	s.done = s.next == 0
+	s.mu.Unlock()
+
+	nonce := streamNonce(seq)
+	return seq, s.aead.Seal(nil, nonce[:], plaintext, additionalData)
+}
+
+// OpenAt decrypts and authenticates the record sealed as number seq.
+func (s *SM4Stream) OpenAt(seq uint64, ciphertext, additionalData []byte) ([]byte, error) {
+	nonce := streamNonce(seq)
+	return s.aead.Open(nil, nonce[:], ciphertext, additionalData)
+}
+
+func streamNonce(seq uint64) [gcmStandardNonceSize]byte {
+	var nonce [gcmStandardNonceSize]byte
+	binary.BigEndian.PutUint64(nonce[4:], seq)
+	return nonce
+}
@@ -0,0 +1,44 @@
+package sm4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Examples from GB/T 32907-2016, appendix A. The second example encrypts the
+// plaintext 1,000,000 times with the same key.
+func TestStandardVectors(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	once, _ := hex.DecodeString("681edf34d206965e86b3e94f536e4246")
+	million, _ := hex.DecodeString("595298c7c6fd271f0402f804c33d3f66")
+
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := append([]byte{}, key...)
+	c.Encrypt(block, block)
+	if !bytes.Equal(block, once) {
+		t.Fatalf("one encryption = %x, want %x", block, once)
+	}
+	if got := Sm4Ecb(key, key, ENC); !bytes.Equal(got[:BlockSize], once) {
+		t.Errorf("Sm4Ecb = %x, want %x", got[:BlockSize], once)
+	}
+
+	if testing.Short() {
+		t.Skip("skipping 1,000,000 iteration example in short mode")
+	}
+	for i := 1; i < 1000000; i++ {
+		c.Encrypt(block, block)
+	}
+	if !bytes.Equal(block, million) {
+		t.Errorf("1,000,000 encryptions = %x, want %x", block, million)
+	}
+	for i := 0; i < 1000000; i++ {
+		c.Decrypt(block, block)
+	}
+	if !bytes.Equal(block, key) {
+		t.Errorf("1,000,000 decryptions = %x, want %x", block, key)
+	}
+}
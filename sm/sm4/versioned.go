@@ -0,0 +1,72 @@
+package sm4
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Versioned ciphertexts have the layout
+//
+//	version || algorithm || nonce || ciphertext || tag
+//
+// where version and algorithm are one byte each. Version 1 with algorithm 1
+// is SM4-GCM with a random 12 byte nonce and a 16 byte tag. The two header
+// bytes are authenticated as part of the additional data.
+const (
+	versionedFormatV1  = 1
+	versionedAlgGCM    = 1
+	versionedHeaderLen = 2
+)
+
+// ErrUnknownVersion is returned by OpenVersioned for a ciphertext whose
+// version or algorithm byte it does not recognise.
+var ErrUnknownVersion = errors.New("sm4: unknown versioned ciphertext format")
+
+// SealVersioned encrypts and authenticates plaintext and additionalData with
+// SM4-GCM under key and returns the result with a version header, so that
+// the format can change later without ambiguity.
+func SealVersioned(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	n := aead.NonceSize()
+	out := make([]byte, versionedHeaderLen+n, versionedHeaderLen+n+len(plaintext)+aead.Overhead())
+	out[0], out[1] = versionedFormatV1, versionedAlgGCM
+	nonce := out[versionedHeaderLen:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, plaintext, versionedAAD(out[:versionedHeaderLen], additionalData)), nil
+}
+
+// OpenVersioned decrypts a ciphertext produced by SealVersioned. Unknown
+// versions or algorithms are reported as ErrUnknownVersion before any
+// authentication is attempted.
+func OpenVersioned(key, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < versionedHeaderLen {
+		return nil, errors.New("sm4: versioned ciphertext too short")
+	}
+	if ciphertext[0] != versionedFormatV1 || ciphertext[1] != versionedAlgGCM {
+		return nil, fmt.Errorf("%w: version %d, algorithm %d", ErrUnknownVersion, ciphertext[0], ciphertext[1])
+	}
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	n := aead.NonceSize()
+	if len(ciphertext) < versionedHeaderLen+n+aead.Overhead() {
+		return nil, errors.New("sm4: versioned ciphertext too short")
+	}
+	header := ciphertext[:versionedHeaderLen]
+	nonce := ciphertext[versionedHeaderLen : versionedHeaderLen+n]
+	return aead.Open(nil, nonce, ciphertext[versionedHeaderLen+n:], versionedAAD(header, additionalData))
+}
+
+func versionedAAD(header, additionalData []byte) []byte {
+	aad := make([]byte, 0, len(header)+len(additionalData))
+	aad = append(aad, header...)
+	return append(aad, additionalData...)
+}
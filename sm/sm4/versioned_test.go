@@ -0,0 +1,46 @@
+package sm4
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVersioned(t *testing.T) {
+	key := decodeHex("0123456789abcdeffedcba9876543210")
+	msg, aad := []byte("stored record"), []byte("row 17")
+	ct, err := SealVersioned(key, msg, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct[0] != 1 || ct[1] != 1 {
+		t.Fatalf("header = %x, want 0101", ct[:2])
+	}
+	got, err := OpenVersioned(key, ct, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("OpenVersioned = %q, want %q", got, msg)
+	}
+
+	for _, i := range []int{0, 1} {
+		bumped := append([]byte{}, ct...)
+		bumped[i]++
+		if _, err := OpenVersioned(key, bumped, aad); !errors.Is(err, ErrUnknownVersion) {
+			t.Errorf("header byte %d bumped: err = %v, want ErrUnknownVersion", i, err)
+		}
+	}
+
+	tampered := append([]byte{}, ct...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := OpenVersioned(key, tampered, aad); err == nil || errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("tampered tag: err = %v, want an authentication error", err)
+	}
+	if _, err := OpenVersioned(key, ct, []byte("row 18")); err == nil {
+		t.Error("OpenVersioned accepted different additional data")
+	}
+	if _, err := OpenVersioned(key, ct[:20], aad); err == nil {
+		t.Error("OpenVersioned accepted a truncated ciphertext")
+	}
+}
@@ -0,0 +1,88 @@
+package x509
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	sm "github.com/flyinox/crypto/sm/sm2"
+)
+
+// PEM block types used for SM2 private keys. "EC PRIVATE KEY" and
+// "SM2 PRIVATE KEY" hold the SEC 1 ECPrivateKey DER; "PRIVATE KEY" holds a
+// PKCS #8 PrivateKeyInfo wrapping it, as in RFC 7468.
+const (
+	PEMTypeSM2PrivateKey   = "SM2 PRIVATE KEY"
+	PEMTypeECPrivateKey    = "EC PRIVATE KEY"
+	PEMTypePKCS8PrivateKey = "PRIVATE KEY"
+)
+
+// WritePrivateKeyToPemSM2Type encodes key as a PEM block of the given type,
+// which must be one of PEMTypeSM2PrivateKey, PEMTypeECPrivateKey or
+// PEMTypePKCS8PrivateKey. Some GM tools only accept PEMTypeSM2PrivateKey.
+func WritePrivateKeyToPemSM2Type(key *sm.PrivateKey, blockType string) ([]byte, error) {
+	der, err := MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	switch blockType {
+	case PEMTypeSM2PrivateKey, PEMTypeECPrivateKey:
+	case PEMTypePKCS8PrivateKey:
+		if der, err = marshalPKCS8SM2(key, der); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("x509: unsupported PEM block type %q for an SM2 private key", blockType)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), nil
+}
+
+// ReadPrivateKeyFromPem decodes the first PEM block in data as an SM2
+// private key. It accepts all three block types written by
+// WritePrivateKeyToPemSM2Type.
+func ReadPrivateKeyFromPem(data []byte) (*sm.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("x509: no PEM block found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case PEMTypeSM2PrivateKey, PEMTypeECPrivateKey:
+		key, err = ParseECPrivateKey(block.Bytes)
+	case PEMTypePKCS8PrivateKey:
+		key, err = ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("x509: unexpected PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(*sm.PrivateKey)
+	if !ok {
+		return nil, errors.New("x509: PEM block does not hold an SM2 private key")
+	}
+	return priv, nil
+}
+
+// marshalPKCS8SM2 wraps the SEC 1 encoding of key in a PKCS #8
+// PrivateKeyInfo naming the SM2 curve.
+func marshalPKCS8SM2(key *sm.PrivateKey, sec1 []byte) ([]byte, error) {
+	oid, ok := oidFromNamedCurve(key.Curve)
+	if !ok {
+		return nil, errors.New("x509: unknown elliptic curve")
+	}
+	params, err := asn1.Marshal(oid)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeySM2,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PrivateKey: sec1,
+	})
+}
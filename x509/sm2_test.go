@@ -0,0 +1,173 @@
+package x509
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/flyinox/crypto/sm/sm2"
+)
+
+func TestParsePKIXPublicKeySM2(t *testing.T) {
+	priv, err := sm2.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := pub.(*sm2.PublicKey); !ok || got.X.Cmp(priv.X) != 0 || got.Y.Cmp(priv.Y) != 0 {
+		t.Fatal("ParsePKIXPublicKey did not round-trip an SM2 key")
+	}
+
+	var pki publicKeyInfo
+	if _, err := asn1.Unmarshal(der, &pki); err != nil {
+		t.Fatal(err)
+	}
+	point := pki.PublicKey.RightAlign()
+	withPoint := func(p []byte) []byte {
+		k := pki
+		k.Raw = nil
+		k.PublicKey = asn1.BitString{Bytes: p, BitLength: 8 * len(p)}
+		b, err := asn1.Marshal(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	offCurve := append([]byte{}, point...)
+	offCurve[64] ^= 1
+	compressed := append([]byte{byte(2 + priv.Y.Bit(0))}, point[1:33]...)
+	identity := make([]byte, 65)
+	identity[0] = 4
+	wrongPrefix := append([]byte{}, point...)
+	wrongPrefix[0] = 6
+
+	for name, p := range map[string][]byte{
+		"off curve":    offCurve,
+		"compressed":   compressed,
+		"identity":     identity,
+		"wrong prefix": wrongPrefix,
+		"truncated":    point[:64],
+		"extended":     append(append([]byte{}, point...), 0),
+	} {
+		if _, err := ParsePKIXPublicKey(withPoint(p)); err == nil {
+			t.Errorf("%s: ParsePKIXPublicKey succeeded", name)
+		}
+	}
+	if !bytes.Equal(withPoint(point), der) {
+		t.Fatal("test re-encoding does not match MarshalPKIXPublicKey")
+	}
+}
+
+func TestPrivateKeyPemTypes(t *testing.T) {
+	priv, err := sm2.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{PEMTypeSM2PrivateKey, PEMTypeECPrivateKey, PEMTypePKCS8PrivateKey} {
+		data, err := WritePrivateKeyToPemSM2Type(priv, typ)
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if block, _ := pem.Decode(data); block == nil || block.Type != typ {
+			t.Errorf("%s: writer produced %q", typ, data)
+		}
+		got, err := ReadPrivateKeyFromPem(data)
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if got.D.Cmp(priv.D) != 0 || got.X.Cmp(priv.X) != 0 || got.Y.Cmp(priv.Y) != 0 {
+			t.Errorf("%s: key did not round-trip", typ)
+		}
+	}
+
+	if _, err := WritePrivateKeyToPemSM2Type(priv, "RSA PRIVATE KEY"); err == nil {
+		t.Error("writer accepted an RSA block type")
+	}
+	data, _ := WritePrivateKeyToPemSM2Type(priv, PEMTypeSM2PrivateKey)
+	block, _ := pem.Decode(data)
+	block.Type = "RSA PRIVATE KEY"
+	if _, err := ReadPrivateKeyFromPem(pem.EncodeToMemory(block)); err == nil {
+		t.Error("reader accepted an RSA block type")
+	}
+}
+
+// sm2TestCert issues a certificate for key, signed by parent and
+// parentKey, or self-signed if parent is nil.
+func sm2TestCert(t *testing.T, cn string, serial int64, key, parentKey *sm2.PrivateKey, parent *Certificate, isCA bool, notAfter time.Time) *Certificate {
+	template := &Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-2 * time.Hour),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              KeyUsageDigitalSignature,
+	}
+	if isCA {
+		template.KeyUsage |= KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestVerifyChain(t *testing.T) {
+	later := time.Now().Add(24 * time.Hour)
+	rootKey, _ := sm2.GenerateKey(nil)
+	interKey, _ := sm2.GenerateKey(nil)
+	leafKey, _ := sm2.GenerateKey(nil)
+	root := sm2TestCert(t, "GM Root", 1, rootKey, nil, nil, true, later)
+	inter := sm2TestCert(t, "GM Intermediate", 2, interKey, rootKey, root, true, later)
+	leaf := sm2TestCert(t, "Document Signer", 3, leafKey, interKey, inter, false, later)
+
+	msg := []byte("contract text")
+	r, s, err := sm2.SignWithHash(nil, leafKey, msg, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, _ := asn1.Marshal(sm2Signature{r, s})
+
+	if err := VerifyChain(leaf, []*Certificate{inter}, []*Certificate{root}, msg, sig); err != nil {
+		t.Fatalf("valid chain: %v", err)
+	}
+	if err := VerifyChain(leaf, []*Certificate{inter}, []*Certificate{root}, []byte("contract text!"), sig); err == nil {
+		t.Error("VerifyChain accepted a signature over a different message")
+	}
+	if err := VerifyChain(leaf, nil, []*Certificate{root}, msg, sig); err == nil {
+		t.Error("VerifyChain accepted a chain missing its intermediate")
+	}
+
+	otherKey, _ := sm2.GenerateKey(nil)
+	other := sm2TestCert(t, "Other Root", 4, otherKey, nil, nil, true, later)
+	if err := VerifyChain(leaf, []*Certificate{inter}, []*Certificate{other}, msg, sig); err == nil {
+		t.Error("VerifyChain accepted an untrusted root")
+	}
+
+	expired := sm2TestCert(t, "Expired Signer", 5, leafKey, interKey, inter, false, time.Now().Add(-time.Hour))
+	err = VerifyChain(expired, []*Certificate{inter}, []*Certificate{root}, msg, sig)
+	if cerr, ok := err.(CertificateInvalidError); !ok || cerr.Reason != Expired {
+		t.Errorf("expired leaf: err = %v, want an Expired CertificateInvalidError", err)
+	}
+}
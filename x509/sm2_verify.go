@@ -0,0 +1,51 @@
+package x509
+
+import (
+	"encoding/asn1"
+	"errors"
+
+	sm "github.com/flyinox/crypto/sm/sm2"
+)
+
+// VerifyChain checks that sig is an SM2 signature of msg by the key in leaf
+// and that leaf chains, through intermediates, to one of roots at the
+// current time. sig is the ASN.1 DER encoding of (r, s) over
+// e = SM3(Z_A || msg) with the default uid, as made by sm2.SignWithHash
+// with h == 0. The leaf may carry any extended key usage.
+//
+// The chain is checked first, so a signature by an untrusted or expired
+// certificate is reported as a chain error whether or not it is valid.
+func VerifyChain(leaf *Certificate, intermediates, roots []*Certificate, msg, sig []byte) error {
+	if leaf == nil {
+		return errors.New("x509: no leaf certificate")
+	}
+	opts := VerifyOptions{
+		Intermediates: NewCertPool(),
+		Roots:         NewCertPool(),
+		KeyUsages:     []ExtKeyUsage{ExtKeyUsageAny},
+	}
+	for _, c := range intermediates {
+		opts.Intermediates.AddCert(c)
+	}
+	for _, c := range roots {
+		opts.Roots.AddCert(c)
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return err
+	}
+
+	pub, ok := leaf.PublicKey.(*sm.PublicKey)
+	if !ok {
+		return errors.New("x509: leaf certificate does not hold an SM2 public key")
+	}
+	sm2Sig := new(sm2Signature)
+	if rest, err := asn1.Unmarshal(sig, sm2Sig); err != nil {
+		return err
+	} else if len(rest) != 0 {
+		return errors.New("x509: trailing data after sm2 signature")
+	}
+	if !sm.VerifyWithHash(pub, msg, nil, 0, sm2Sig.R, sm2Sig.S) {
+		return errors.New("x509: sm2 verification failure")
+	}
+	return nil
+}
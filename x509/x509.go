@@ -74,7 +74,10 @@ func ParsePKIXPublicKey(derBytes []byte) (pub interface{}, err error) {
 func marshalPublicKey(pub interface{}) (publicKeyBytes []byte, publicKeyAlgorithm pkix.AlgorithmIdentifier, err error) {
 	switch pub := pub.(type) {
 	case *sm2.PublicKey:
-		publicKeyBytes = elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+		publicKeyBytes, err = pub.Marshal()
+		if err != nil {
+			return nil, pkix.AlgorithmIdentifier{}, errors.New("x509: invalid SM2 public key")
+		}
 		oid, ok := oidFromNamedCurve(pub.Curve)
 		if !ok {
 			return nil, pkix.AlgorithmIdentifier{}, errors.New("x509: unsupported elliptic curve")
@@ -1027,15 +1030,28 @@ func parsePublicKey(algo PublicKeyAlgorithm, keyData *publicKeyInfo) (interface{
 		if namedCurve == nil {
 			return nil, errors.New("x509: unsupported SM2 elliptic curve")
 		}
+		// The point must be uncompressed, on the curve and not the
+		// identity. SM2 has cofactor 1, so every such point has order N;
+		// CheckOrder confirms it explicitly.
+		byteLen := (namedCurve.Params().BitSize + 7) / 8
+		if len(asn1Data) != 1+2*byteLen {
+			return nil, errors.New("x509: SM2 public key has the wrong length")
+		}
+		if asn1Data[0] != 4 {
+			return nil, errors.New("x509: SM2 public key is not in uncompressed form")
+		}
 		x, y := elliptic.Unmarshal(namedCurve, asn1Data)
 		if x == nil {
-			return nil, errors.New("x509: failed to unmarshal elliptic curve point")
+			return nil, errors.New("x509: SM2 public key is not on the curve")
 		}
 		pub := &sm2.PublicKey{
 			Curve: namedCurve,
 			X:     x,
 			Y:     y,
 		}
+		if err := pub.CheckOrder(); err != nil {
+			return nil, errors.New("x509: invalid SM2 public key: " + err.Error())
+		}
 		return pub, nil
 	case RSA:
 		// RSA public keys must have a NULL in the parameters